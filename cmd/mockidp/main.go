@@ -0,0 +1,37 @@
+// Command mockidp is a tiny OpenID Connect provider for testing SSO locally.
+// It approves every login without asking and signs ID tokens with a key
+// generated at startup. Never use it in production.
+//
+//	go run ./cmd/mockidp
+//	OIDC_ISSUER=http://localhost:9000 OIDC_CLIENT_ID=pdfms OIDC_CLIENT_SECRET=secret go run ./cmd/server
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"pdf-management-system/internal/oidc/oidctest"
+	"strings"
+)
+
+func main() {
+	port := getenv("MOCKIDP_PORT", "9000")
+	p, err := oidctest.New(getenv("MOCKIDP_CLIENT_ID", "pdfms"), getenv("MOCKIDP_CLIENT_SECRET", "secret"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	p.Issuer = getenv("MOCKIDP_ISSUER", "http://localhost:"+port)
+	p.Email = getenv("MOCKIDP_EMAIL", "sso.user@example.com")
+	p.Name = getenv("MOCKIDP_NAME", "SSO User")
+	p.Groups = strings.Fields(strings.ReplaceAll(getenv("MOCKIDP_GROUPS", "pdf-staff"), ",", " "))
+
+	log.Printf("Mock IdP %s (client %s, user %s, groups %v)", p.Issuer, p.ClientID, p.Email, p.Groups)
+	log.Fatal(http.ListenAndServe(":"+port, p))
+}
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"pdf-management-system/internal/metrics"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/repository"
+)
+
+// registerMetrics adds the gauges read at scrape time: DB pool, disk usage and
+// stored PDFs per status.
+func registerMetrics(db *sql.DB, pdfRepo *repository.PdfRepository, storageDir string) {
+	metrics.RegisterDBStats(db)
+	metrics.RegisterDiskUsage(storageDir)
+
+	pdfStats := func(value func(model.PdfStatusStats) int64) func() []metrics.Sample {
+		return func() []metrics.Sample {
+			// Dibaca saat scrape, dibatasi timeout query repository
+			stats, err := pdfRepo.StatsByStatus(context.Background())
+			if err != nil {
+				slog.Error("failed to read PDF stats for metrics", "error", err)
+				return nil
+			}
+			samples := []metrics.Sample{}
+			for _, s := range stats {
+				samples = append(samples, metrics.Sample{LabelValues: []string{string(s.Status)}, Value: float64(value(s))})
+			}
+			return samples
+		}
+	}
+	metrics.NewGaugeFunc("pdfms_pdf_stored_bytes", "Total size of PDF files by status.",
+		pdfStats(func(s model.PdfStatusStats) int64 { return s.Bytes }), "status")
+	// PENDING_SCAN adalah antrian scan yang diproses ulang oleh RunRescanLoop
+	metrics.NewGaugeFunc("pdfms_pdf_files", "Number of PDF files by status; PENDING_SCAN is the rescan queue.",
+		pdfStats(func(s model.PdfStatusStats) int64 { return s.Files }), "status")
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/migrate"
+	"strconv"
+)
+
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up            apply all pending migrations
+  down [n]      roll back the last n migrations (default 1)
+  status        list migrations and whether they are applied
+  to <version>  migrate up or down to exactly <version> (0 rolls back everything)`
+
+// runMigrate handles `server migrate ...`.
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+
+	// Hanya butuh konfigurasi DB, JWT dll. tidak wajib untuk migrate
+	if err := cfg.DB.Validate(); err != nil {
+		configFatal(err)
+	}
+	ctx := context.Background()
+	db, err := config.ConnectDB(ctx, cfg.DB)
+	if err != nil {
+		fatal("database unavailable", err)
+	}
+	defer db.Close()
+
+	m, err := migrate.New(db)
+	if err != nil {
+		fatal("failed to load migrations", err)
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := m.Up(ctx)
+		if err != nil {
+			fatal("migration failed", err)
+		}
+		slog.Info("migrations applied", "count", n)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				fatal("invalid step count", err, "value", args[1])
+			}
+		}
+		n, err := m.Down(ctx, steps)
+		if err != nil {
+			fatal("rollback failed", err)
+		}
+		slog.Info("migrations rolled back", "count", n)
+
+	case "to":
+		if len(args) < 2 {
+			fatal("migrate to: missing version", nil)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			fatal("invalid version", err, "value", args[1])
+		}
+		n, err := m.To(ctx, version)
+		if err != nil {
+			fatal("migration failed", err)
+		}
+		slog.Info("migrated", "version", version, "changes", n)
+
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			fatal("failed to read migration status", err)
+		}
+		for _, st := range statuses {
+			applied := "pending"
+			if st.Applied {
+				applied = "applied " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s %s\n", st.Version, st.Name, applied)
+		}
+
+	default:
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+}
+
+// migrateOnStart brings the schema up to date before the server accepts requests.
+func migrateOnStart(ctx context.Context, db *sql.DB) {
+	m, err := migrate.New(db)
+	if err != nil {
+		fatal("failed to load migrations", err)
+	}
+	n, err := m.Up(ctx)
+	if err != nil {
+		fatal("failed to migrate database", err)
+	}
+	if n > 0 {
+		slog.Info("migrations applied", "count", n, "version", m.Latest())
+	}
+}
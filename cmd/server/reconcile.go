@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/repository"
+	"pdf-management-system/internal/service"
+)
+
+const reconcileUsage = `usage: server reconcile [--fix]
+
+Compares files in the PDF storage and quarantine directories with pdf_files.
+Without --fix it only reports; with --fix files without a row are deleted and
+rows without a file are marked DELETED. Exits 1 if mismatches remain.`
+
+// runReconcile handles `server reconcile ...`.
+func runReconcile(cfg *config.Config, args []string) {
+	fix := false
+	for _, arg := range args {
+		switch arg {
+		case "--fix":
+			fix = true
+		default:
+			fmt.Println(reconcileUsage)
+			os.Exit(2)
+		}
+	}
+
+	if err := cfg.DB.Validate(); err != nil {
+		configFatal(err)
+	}
+	ctx := context.Background()
+	db, err := config.ConnectDB(ctx, cfg.DB)
+	if err != nil {
+		fatal("database unavailable", err)
+	}
+	defer db.Close()
+
+	pdfService := service.NewPdfService(repository.NewPdfRepository(db), nil, cfg)
+	report, err := pdfService.Reconcile(ctx, fix)
+	if err != nil {
+		fatal("reconcile failed", err)
+	}
+
+	action := "found"
+	if fix {
+		action = "fixed"
+	}
+	for _, path := range report.OrphanFiles {
+		fmt.Printf("orphan file     %s  %s\n", action, path)
+	}
+	for _, path := range report.StaleTempFiles {
+		fmt.Printf("stale temp file %s  %s\n", action, path)
+	}
+	for _, pdf := range report.MissingFiles {
+		fmt.Printf("missing file    %s  id=%d %s (%s)\n", action, pdf.ID, pdf.Filename, pdf.Status)
+	}
+	fmt.Printf("%d orphan files, %d stale temp files, %d rows with missing files\n",
+		len(report.OrphanFiles), len(report.StaleTempFiles), len(report.MissingFiles))
+
+	if !fix && !report.Clean() {
+		db.Close()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,688 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is every setting the server needs, loaded once at startup by Load
+// and passed to the constructors; nothing else reads the environment.
+type Config struct {
+	Server     ServerConfig     `yaml:"server"`
+	DB         DBConfig         `yaml:"database"`
+	JWT        JWTConfig        `yaml:"jwt"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Admin      AdminConfig      `yaml:"admin"`
+	OIDC       OIDCConfig       `yaml:"oidc"`
+	LoginGuard LoginGuardConfig `yaml:"login_guard"`
+	Mail       MailConfig       `yaml:"mail"`
+	Storage    StorageConfig    `yaml:"storage"`
+	Scanner    ScannerConfig    `yaml:"scanner"`
+	Upload     UploadConfig     `yaml:"upload"`
+	Preview    PreviewConfig    `yaml:"preview"`
+	Share      ShareConfig      `yaml:"share"`
+	Log        LogConfig        `yaml:"log"`
+}
+
+type ServerConfig struct {
+	Port              int           `yaml:"port"`
+	BaseURL           string        `yaml:"base_url"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	MetricsToken      string        `yaml:"metrics_token"`
+}
+
+type DBConfig struct {
+	Host            string        `yaml:"host"`
+	Port            int           `yaml:"port"`
+	User            string        `yaml:"user"`
+	Password        string        `yaml:"password"`
+	Name            string        `yaml:"name"`
+	SSLMode         string        `yaml:"sslmode"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	ConnectAttempts int           `yaml:"connect_attempts"`
+}
+
+type JWTConfig struct {
+	// Secret signs HS256 tokens; with PrivateKeyFile set it is only used to verify old tokens
+	Secret          string        `yaml:"secret"`
+	PrivateKeyFile  string        `yaml:"private_key_file"`
+	VerifyKeyFiles  []string      `yaml:"verify_key_files"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+}
+
+type AuthConfig struct {
+	// off, login or pdf, see service.VerificationMode
+	EmailVerification string        `yaml:"email_verification"`
+	VerificationTTL   time.Duration `yaml:"verification_ttl"`
+	PasswordResetTTL  time.Duration `yaml:"password_reset_ttl"`
+	PasswordResetURL  string        `yaml:"password_reset_url"`
+	DefaultRole       string        `yaml:"default_role"`
+}
+
+// AdminConfig is the account created at startup while there is no active admin.
+type AdminConfig struct {
+	Email    string `yaml:"email"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+}
+
+// OIDCConfig enables single sign-on when Issuer is set.
+type OIDCConfig struct {
+	Issuer       string `yaml:"issuer"`
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	// Empty means BaseURL + /api/auth/oidc/callback
+	RedirectURL string   `yaml:"redirect_url"`
+	Scopes      []string `yaml:"scopes"`
+	GroupsClaim string   `yaml:"groups_claim"`
+	// Checked in order, the first group the user is in decides the role
+	RoleMapping       []OIDCRoleMapping `yaml:"role_mapping"`
+	PostLoginRedirect string            `yaml:"post_login_redirect"`
+}
+
+// OIDCRoleMapping maps one IdP group to one of our role names.
+type OIDCRoleMapping struct {
+	Group string `yaml:"group"`
+	Role  string `yaml:"role"`
+}
+
+// LoginGuardConfig throttles failed logins, see service.LoginGuard.
+type LoginGuardConfig struct {
+	// memory (single instance) or postgres
+	Store            string        `yaml:"store"`
+	MaxAttempts      int           `yaml:"max_attempts"`
+	MaxAttemptsPerIP int           `yaml:"max_attempts_per_ip"`
+	LockoutBase      time.Duration `yaml:"lockout_base"`
+	LockoutMax       time.Duration `yaml:"lockout_max"`
+	Window           time.Duration `yaml:"window"`
+}
+
+type MailConfig struct {
+	// log or smtp
+	Mailer string `yaml:"mailer"`
+	From   string `yaml:"from"`
+	// Used by the log mailer, empty writes to the server log
+	LogFile      string `yaml:"log_file"`
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     int    `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+}
+
+type StorageConfig struct {
+	PdfDir         string  `yaml:"pdf_dir"`
+	QuarantineDir  string  `yaml:"quarantine_dir"`
+	UploadTmpDir   string  `yaml:"upload_tmp_dir"`
+	MinFreeBytes   uint64  `yaml:"min_free_bytes"`
+	MinFreePercent float64 `yaml:"min_free_percent"`
+}
+
+type UploadConfig struct {
+	// Direct multipart upload (POST /api/pdf/upload)
+	MaxFileSize int64 `yaml:"max_file_size"`
+	MaxFiles    int   `yaml:"max_files"`
+	// Resumable upload
+	MaxSize       int64           `yaml:"max_size"`
+	MaxSizeByRole map[int64]int64 `yaml:"max_size_by_role"`
+	ChunkSize     int64           `yaml:"chunk_size"`
+	SessionTTL    time.Duration   `yaml:"session_ttl"`
+	// Import from URL
+	ImportMaxSize      int64    `yaml:"import_max_size"`
+	ImportAllowedPorts []string `yaml:"import_allowed_ports"`
+	ImportAllowPrivate bool     `yaml:"import_allow_private"`
+}
+
+type ScannerConfig struct {
+	// noop or clamd
+	Name string `yaml:"name"`
+	// tcp://host:port or unix:///path/to/clamd.ctl
+	ClamdAddress string `yaml:"clamd_address"`
+}
+
+type PreviewConfig struct {
+	// pdftoppm, mutool or a path to one of them; empty uses the first found on PATH
+	Renderer string `yaml:"renderer"`
+}
+
+type LogConfig struct {
+	// debug, info, warn or error
+	Level string `yaml:"level"`
+	// json or text
+	Format string `yaml:"format"`
+}
+
+// ShareConfig limits public share links (POST /api/pdf/{id}/share).
+type ShareConfig struct {
+	DefaultTTL time.Duration `yaml:"default_ttl"`
+	MaxTTL     time.Duration `yaml:"max_ttl"`
+	// Link is revoked after this many wrong passwords
+	MaxPasswordFailures int `yaml:"max_password_failures"`
+}
+
+// Default returns the built-in defaults. DB user/name and a JWT key have no default.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              8080,
+			BaseURL:           "http://localhost:8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       2 * time.Minute,
+			WriteTimeout:      2 * time.Minute,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		DB: DBConfig{
+			Host:            "localhost",
+			Port:            5432,
+			SSLMode:         "require",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectAttempts: 10,
+		},
+		JWT: JWTConfig{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+		},
+		Auth: AuthConfig{
+			EmailVerification: "off",
+			VerificationTTL:   24 * time.Hour,
+			PasswordResetTTL:  time.Hour,
+			DefaultRole:       "Staff",
+		},
+		Admin: AdminConfig{
+			Name: "Administrator",
+		},
+		OIDC: OIDCConfig{
+			Scopes:      []string{"openid", "email", "profile"},
+			GroupsClaim: "groups",
+		},
+		LoginGuard: LoginGuardConfig{
+			Store:            "memory",
+			MaxAttempts:      5,
+			MaxAttemptsPerIP: 20,
+			LockoutBase:      time.Minute,
+			LockoutMax:       time.Hour,
+			Window:           time.Hour,
+		},
+		Mail: MailConfig{
+			Mailer:   "log",
+			From:     "no-reply@localhost",
+			SMTPPort: 587,
+		},
+		Storage: StorageConfig{
+			PdfDir:        "uploads/pdf",
+			QuarantineDir: "quarantine",
+			// Di luar folder uploads/ supaya file parsial tidak ikut dilayani file server
+			UploadTmpDir: "tmp/uploads",
+			MinFreeBytes: 100 << 20,
+		},
+		Scanner: ScannerConfig{
+			Name:         "noop",
+			ClamdAddress: "tcp://127.0.0.1:3310",
+		},
+		Upload: UploadConfig{
+			MaxFileSize:        10 << 20,
+			MaxFiles:           20,
+			MaxSize:            100 << 20,
+			ChunkSize:          5 << 20,
+			SessionTTL:         24 * time.Hour,
+			ImportMaxSize:      25 << 20,
+			ImportAllowedPorts: []string{"80", "443"},
+		},
+		Share: ShareConfig{
+			DefaultTTL:          7 * 24 * time.Hour,
+			MaxTTL:              30 * 24 * time.Hour,
+			MaxPasswordFailures: 10,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+}
+
+// Load builds the config from the defaults, the YAML file in CONFIG_FILE (if
+// set) and finally the environment, so env vars (and .env) win over the file.
+// The result still has to be checked with Validate.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("config file: %w", err)
+		}
+		defer f.Close()
+
+		dec := yaml.NewDecoder(f)
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	var e envReader
+	e.int("PORT", &cfg.Server.Port)
+	e.string("APP_BASE_URL", &cfg.Server.BaseURL)
+	e.duration("HTTP_READ_HEADER_TIMEOUT", &cfg.Server.ReadHeaderTimeout)
+	e.duration("HTTP_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	e.duration("HTTP_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	e.duration("HTTP_IDLE_TIMEOUT", &cfg.Server.IdleTimeout)
+	e.duration("SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+	e.string("METRICS_TOKEN", &cfg.Server.MetricsToken)
+
+	e.string("DB_HOST", &cfg.DB.Host)
+	e.int("DB_PORT", &cfg.DB.Port)
+	e.string("DB_USER", &cfg.DB.User)
+	e.string("DB_PASSWORD", &cfg.DB.Password)
+	e.string("DB_NAME", &cfg.DB.Name)
+	e.string("DB_SSLMODE", &cfg.DB.SSLMode)
+	e.int("DB_MAX_OPEN_CONNS", &cfg.DB.MaxOpenConns)
+	e.int("DB_MAX_IDLE_CONNS", &cfg.DB.MaxIdleConns)
+	e.duration("DB_CONN_MAX_LIFETIME", &cfg.DB.ConnMaxLifetime)
+	e.duration("DB_CONN_MAX_IDLE_TIME", &cfg.DB.ConnMaxIdleTime)
+	e.int("DB_CONNECT_ATTEMPTS", &cfg.DB.ConnectAttempts)
+
+	e.string("JWT_SECRET", &cfg.JWT.Secret)
+	e.string("JWT_PRIVATE_KEY_FILE", &cfg.JWT.PrivateKeyFile)
+	e.list("JWT_VERIFY_KEY_FILES", &cfg.JWT.VerifyKeyFiles)
+	e.duration("ACCESS_TOKEN_TTL", &cfg.JWT.AccessTokenTTL)
+	e.duration("REFRESH_TOKEN_TTL", &cfg.JWT.RefreshTokenTTL)
+
+	e.string("REQUIRE_EMAIL_VERIFICATION", &cfg.Auth.EmailVerification)
+	e.duration("EMAIL_VERIFICATION_TTL", &cfg.Auth.VerificationTTL)
+	e.duration("PASSWORD_RESET_TTL", &cfg.Auth.PasswordResetTTL)
+	e.string("PASSWORD_RESET_URL", &cfg.Auth.PasswordResetURL)
+	e.string("DEFAULT_ROLE", &cfg.Auth.DefaultRole)
+
+	e.string("ADMIN_EMAIL", &cfg.Admin.Email)
+	e.string("ADMIN_PASSWORD", &cfg.Admin.Password)
+	e.string("ADMIN_NAME", &cfg.Admin.Name)
+
+	e.string("OIDC_ISSUER", &cfg.OIDC.Issuer)
+	e.string("OIDC_CLIENT_ID", &cfg.OIDC.ClientID)
+	e.string("OIDC_CLIENT_SECRET", &cfg.OIDC.ClientSecret)
+	e.string("OIDC_REDIRECT_URL", &cfg.OIDC.RedirectURL)
+	e.fields("OIDC_SCOPES", &cfg.OIDC.Scopes)
+	e.string("OIDC_GROUPS_CLAIM", &cfg.OIDC.GroupsClaim)
+	e.roleMapping("OIDC_ROLE_MAPPING", &cfg.OIDC.RoleMapping)
+	e.string("OIDC_POST_LOGIN_REDIRECT", &cfg.OIDC.PostLoginRedirect)
+
+	e.string("LOGIN_ATTEMPT_STORE", &cfg.LoginGuard.Store)
+	e.int("LOGIN_MAX_ATTEMPTS", &cfg.LoginGuard.MaxAttempts)
+	e.int("LOGIN_MAX_ATTEMPTS_PER_IP", &cfg.LoginGuard.MaxAttemptsPerIP)
+	e.duration("LOGIN_LOCKOUT_BASE", &cfg.LoginGuard.LockoutBase)
+	e.duration("LOGIN_LOCKOUT_MAX", &cfg.LoginGuard.LockoutMax)
+	e.duration("LOGIN_ATTEMPT_WINDOW", &cfg.LoginGuard.Window)
+
+	e.string("MAILER", &cfg.Mail.Mailer)
+	e.string("MAIL_FROM", &cfg.Mail.From)
+	e.string("MAIL_LOG_FILE", &cfg.Mail.LogFile)
+	e.string("SMTP_HOST", &cfg.Mail.SMTPHost)
+	e.int("SMTP_PORT", &cfg.Mail.SMTPPort)
+	e.string("SMTP_USERNAME", &cfg.Mail.SMTPUsername)
+	e.string("SMTP_PASSWORD", &cfg.Mail.SMTPPassword)
+
+	e.string("STORAGE_PDF_DIR", &cfg.Storage.PdfDir)
+	e.string("QUARANTINE_DIR", &cfg.Storage.QuarantineDir)
+	e.string("UPLOAD_TMP_DIR", &cfg.Storage.UploadTmpDir)
+	e.uint("MIN_FREE_DISK_BYTES", &cfg.Storage.MinFreeBytes)
+	e.float("MIN_FREE_DISK_PERCENT", &cfg.Storage.MinFreePercent)
+
+	e.string("SCANNER", &cfg.Scanner.Name)
+	e.string("CLAMD_ADDRESS", &cfg.Scanner.ClamdAddress)
+
+	e.int64("UPLOAD_MAX_FILE_SIZE", &cfg.Upload.MaxFileSize)
+	e.int("UPLOAD_MAX_FILES", &cfg.Upload.MaxFiles)
+	e.int64("UPLOAD_MAX_SIZE", &cfg.Upload.MaxSize)
+	e.roleSizes("UPLOAD_MAX_SIZE_BY_ROLE", &cfg.Upload.MaxSizeByRole)
+	e.int64("UPLOAD_CHUNK_SIZE", &cfg.Upload.ChunkSize)
+	e.duration("UPLOAD_SESSION_TTL", &cfg.Upload.SessionTTL)
+	e.int64("IMPORT_MAX_SIZE", &cfg.Upload.ImportMaxSize)
+	e.list("IMPORT_ALLOWED_PORTS", &cfg.Upload.ImportAllowedPorts)
+	e.bool("IMPORT_ALLOW_PRIVATE", &cfg.Upload.ImportAllowPrivate)
+
+	e.string("PREVIEW_RENDERER", &cfg.Preview.Renderer)
+
+	e.duration("SHARE_DEFAULT_TTL", &cfg.Share.DefaultTTL)
+	e.duration("SHARE_MAX_TTL", &cfg.Share.MaxTTL)
+	e.int("SHARE_MAX_PASSWORD_FAILURES", &cfg.Share.MaxPasswordFailures)
+
+	e.string("LOG_LEVEL", &cfg.Log.Level)
+	e.string("LOG_FORMAT", &cfg.Log.Format)
+
+	if len(e.errs) > 0 {
+		return nil, errors.Join(e.errs...)
+	}
+	return cfg, nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "PORT must be between 1 and 65535, got %d", c.Server.Port)
+	check(isHTTPURL(c.Server.BaseURL), "APP_BASE_URL must start with http:// or https://, got %q", c.Server.BaseURL)
+	for name, d := range map[string]time.Duration{
+		"HTTP_READ_HEADER_TIMEOUT": c.Server.ReadHeaderTimeout,
+		"HTTP_READ_TIMEOUT":        c.Server.ReadTimeout,
+		"HTTP_WRITE_TIMEOUT":       c.Server.WriteTimeout,
+		"HTTP_IDLE_TIMEOUT":        c.Server.IdleTimeout,
+		"SHUTDOWN_TIMEOUT":         c.Server.ShutdownTimeout,
+		"ACCESS_TOKEN_TTL":         c.JWT.AccessTokenTTL,
+		"REFRESH_TOKEN_TTL":        c.JWT.RefreshTokenTTL,
+		"EMAIL_VERIFICATION_TTL":   c.Auth.VerificationTTL,
+		"PASSWORD_RESET_TTL":       c.Auth.PasswordResetTTL,
+		"UPLOAD_SESSION_TTL":       c.Upload.SessionTTL,
+		"SHARE_DEFAULT_TTL":        c.Share.DefaultTTL,
+		"SHARE_MAX_TTL":            c.Share.MaxTTL,
+		"LOGIN_LOCKOUT_BASE":       c.LoginGuard.LockoutBase,
+		"LOGIN_LOCKOUT_MAX":        c.LoginGuard.LockoutMax,
+		"LOGIN_ATTEMPT_WINDOW":     c.LoginGuard.Window,
+	} {
+		check(d > 0, "%s must be a positive duration, got %s", name, d)
+	}
+
+	if err := c.DB.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	check(c.JWT.Secret != "" || c.JWT.PrivateKeyFile != "", "no JWT signing key configured: set JWT_PRIVATE_KEY_FILE or JWT_SECRET")
+	check(c.JWT.Secret == "" || len(c.JWT.Secret) >= 32, "JWT_SECRET must be at least 32 characters")
+	check(c.JWT.RefreshTokenTTL >= c.JWT.AccessTokenTTL, "REFRESH_TOKEN_TTL must not be shorter than ACCESS_TOKEN_TTL")
+
+	switch c.Auth.EmailVerification {
+	case "off", "login", "pdf":
+	default:
+		errs = append(errs, fmt.Errorf("REQUIRE_EMAIL_VERIFICATION must be off, login or pdf, got %q", c.Auth.EmailVerification))
+	}
+	check(c.Auth.DefaultRole != "", "DEFAULT_ROLE must not be empty")
+
+	check((c.Admin.Email == "") == (c.Admin.Password == ""), "ADMIN_EMAIL and ADMIN_PASSWORD must be set together")
+	check(c.Admin.Email == "" || strings.Contains(c.Admin.Email, "@"), "ADMIN_EMAIL must be an email address, got %q", c.Admin.Email)
+	check(c.Admin.Name != "", "ADMIN_NAME must not be empty")
+
+	if c.OIDC.Issuer != "" {
+		check(isHTTPURL(c.OIDC.Issuer), "OIDC_ISSUER must start with http:// or https://, got %q", c.OIDC.Issuer)
+		check(c.OIDC.ClientID != "", "OIDC_CLIENT_ID is required when OIDC_ISSUER is set")
+		check(c.OIDC.RedirectURL == "" || isHTTPURL(c.OIDC.RedirectURL), "OIDC_REDIRECT_URL must start with http:// or https://, got %q", c.OIDC.RedirectURL)
+		check(slices.Contains(c.OIDC.Scopes, "openid"), "OIDC_SCOPES must include openid, got %q", strings.Join(c.OIDC.Scopes, " "))
+		check(c.OIDC.GroupsClaim != "", "OIDC_GROUPS_CLAIM must not be empty")
+		for _, m := range c.OIDC.RoleMapping {
+			check(m.Group != "" && m.Role != "", "OIDC_ROLE_MAPPING: group and role must not be empty, got %q=%q", m.Group, m.Role)
+		}
+	}
+
+	switch c.LoginGuard.Store {
+	case "memory", "postgres":
+	default:
+		errs = append(errs, fmt.Errorf("LOGIN_ATTEMPT_STORE must be memory or postgres, got %q", c.LoginGuard.Store))
+	}
+	check(c.LoginGuard.MaxAttempts > 0, "LOGIN_MAX_ATTEMPTS must be positive")
+	check(c.LoginGuard.MaxAttemptsPerIP > 0, "LOGIN_MAX_ATTEMPTS_PER_IP must be positive")
+	check(c.LoginGuard.LockoutMax >= c.LoginGuard.LockoutBase, "LOGIN_LOCKOUT_MAX must not be shorter than LOGIN_LOCKOUT_BASE")
+
+	switch c.Mail.Mailer {
+	case "log":
+	case "smtp":
+		check(c.Mail.SMTPHost != "", "SMTP_HOST is required when MAILER=smtp")
+		check(c.Mail.SMTPPort > 0 && c.Mail.SMTPPort <= 65535, "SMTP_PORT must be between 1 and 65535, got %d", c.Mail.SMTPPort)
+	default:
+		errs = append(errs, fmt.Errorf("MAILER must be log or smtp, got %q", c.Mail.Mailer))
+	}
+	check(strings.Contains(c.Mail.From, "@"), "MAIL_FROM must be an email address, got %q", c.Mail.From)
+
+	check(c.Storage.PdfDir != "", "STORAGE_PDF_DIR must not be empty")
+	check(c.Storage.QuarantineDir != "", "QUARANTINE_DIR must not be empty")
+	check(c.Storage.UploadTmpDir != "", "UPLOAD_TMP_DIR must not be empty")
+	check(c.Storage.MinFreePercent >= 0 && c.Storage.MinFreePercent < 100, "MIN_FREE_DISK_PERCENT must be between 0 and 100")
+
+	switch c.Scanner.Name {
+	case "noop":
+	case "clamd":
+		check(strings.HasPrefix(c.Scanner.ClamdAddress, "tcp://") || strings.HasPrefix(c.Scanner.ClamdAddress, "unix://"),
+			"CLAMD_ADDRESS must start with tcp:// or unix://, got %q", c.Scanner.ClamdAddress)
+	default:
+		errs = append(errs, fmt.Errorf("SCANNER must be noop or clamd, got %q", c.Scanner.Name))
+	}
+
+	check(c.Upload.MaxFileSize > 0, "UPLOAD_MAX_FILE_SIZE must be positive")
+	check(c.Upload.MaxFiles > 0, "UPLOAD_MAX_FILES must be positive")
+	check(c.Upload.MaxSize > 0, "UPLOAD_MAX_SIZE must be positive")
+	check(c.Upload.ChunkSize > 0, "UPLOAD_CHUNK_SIZE must be positive")
+	check(c.Upload.ImportMaxSize > 0, "IMPORT_MAX_SIZE must be positive")
+	for roleID, size := range c.Upload.MaxSizeByRole {
+		check(size > 0, "UPLOAD_MAX_SIZE_BY_ROLE: size for role %d must be positive", roleID)
+	}
+	for _, p := range c.Upload.ImportAllowedPorts {
+		n, err := strconv.Atoi(p)
+		check(err == nil && n > 0 && n <= 65535, "IMPORT_ALLOWED_PORTS: invalid port %q", p)
+	}
+
+	switch filepath.Base(c.Preview.Renderer) {
+	case ".", "pdftoppm", "mutool":
+	default:
+		errs = append(errs, fmt.Errorf("PREVIEW_RENDERER must be pdftoppm or mutool, got %q", c.Preview.Renderer))
+	}
+
+	check(c.Share.MaxTTL >= c.Share.DefaultTTL, "SHARE_MAX_TTL must not be shorter than SHARE_DEFAULT_TTL")
+	check(c.Share.MaxPasswordFailures > 0, "SHARE_MAX_PASSWORD_FAILURES must be positive")
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.Log.Level))
+	}
+	check(c.Log.Format == "json" || c.Log.Format == "text", "LOG_FORMAT must be json or text, got %q", c.Log.Format)
+
+	return errors.Join(errs...)
+}
+
+func isHTTPURL(v string) bool {
+	return strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://")
+}
+
+// Validate checks only the database settings, enough for `server migrate`.
+func (c DBConfig) Validate() error {
+	var errs []error
+	if c.Host == "" {
+		errs = append(errs, errors.New("DB_HOST must not be empty"))
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("DB_PORT must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.User == "" {
+		errs = append(errs, errors.New("DB_USER is required"))
+	}
+	if c.Name == "" {
+		errs = append(errs, errors.New("DB_NAME is required"))
+	}
+	// Mode yang didukung lib/pq
+	switch c.SSLMode {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Errorf("DB_SSLMODE must be disable, require, verify-ca or verify-full, got %q", c.SSLMode))
+	}
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must not be negative"))
+	}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", c.MaxIdleConns, c.MaxOpenConns))
+	}
+	if c.ConnectAttempts < 1 {
+		errs = append(errs, errors.New("DB_CONNECT_ATTEMPTS must be at least 1"))
+	}
+	return errors.Join(errs...)
+}
+
+// envReader overrides config values from env vars that are set, collecting
+// parse errors instead of silently falling back to the default.
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) lookup(key string) (string, bool) {
+	v, ok := os.LookupEnv(key)
+	if !ok || strings.TrimSpace(v) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(v), true
+}
+
+func (e *envReader) fail(key, v, want string) {
+	e.errs = append(e.errs, fmt.Errorf("%s: %q is not a valid %s", key, v, want))
+}
+
+func (e *envReader) string(key string, dst *string) {
+	// Nilai tidak di-trim, password/secret boleh berisi spasi
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		*dst = v
+	}
+}
+
+func (e *envReader) int(key string, dst *int) {
+	if v, ok := e.lookup(key); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			e.fail(key, v, "integer")
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *envReader) int64(key string, dst *int64) {
+	if v, ok := e.lookup(key); ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			e.fail(key, v, "integer")
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *envReader) uint(key string, dst *uint64) {
+	if v, ok := e.lookup(key); ok {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			e.fail(key, v, "non-negative integer")
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *envReader) float(key string, dst *float64) {
+	if v, ok := e.lookup(key); ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			e.fail(key, v, "number")
+			return
+		}
+		*dst = f
+	}
+}
+
+func (e *envReader) bool(key string, dst *bool) {
+	if v, ok := e.lookup(key); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			e.fail(key, v, "boolean")
+			return
+		}
+		*dst = b
+	}
+}
+
+func (e *envReader) duration(key string, dst *time.Duration) {
+	if v, ok := e.lookup(key); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			e.fail(key, v, "duration (e.g. 30s, 15m, 24h)")
+			return
+		}
+		*dst = d
+	}
+}
+
+func (e *envReader) list(key string, dst *[]string) {
+	if v, ok := e.lookup(key); ok {
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*dst = items
+	}
+}
+
+// fields parses a space separated list, e.g. "openid email profile".
+func (e *envReader) fields(key string, dst *[]string) {
+	if v, ok := e.lookup(key); ok {
+		*dst = strings.Fields(v)
+	}
+}
+
+// roleMapping parses "group=Role,group2=Role2".
+func (e *envReader) roleMapping(key string, dst *[]OIDCRoleMapping) {
+	v, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	var mappings []OIDCRoleMapping
+	for _, pair := range strings.Split(v, ",") {
+		group, role, ok := strings.Cut(pair, "=")
+		group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+		if !ok || group == "" || role == "" {
+			e.fail(key, pair, "group=role entry")
+			continue
+		}
+		mappings = append(mappings, OIDCRoleMapping{Group: group, Role: role})
+	}
+	*dst = mappings
+}
+
+// roleSizes parses "1=524288000,2=104857600" (role_id=bytes).
+func (e *envReader) roleSizes(key string, dst *map[int64]int64) {
+	v, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	sizes := make(map[int64]int64)
+	for _, pair := range strings.Split(v, ",") {
+		roleStr, sizeStr, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		roleID, err1 := strconv.ParseInt(roleStr, 10, 64)
+		size, err2 := strconv.ParseInt(sizeStr, 10, 64)
+		if !ok || err1 != nil || err2 != nil {
+			e.fail(key, pair, "role_id=bytes entry")
+			continue
+		}
+		sizes[roleID] = size
+	}
+	*dst = sizes
+}
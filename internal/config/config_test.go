@@ -0,0 +1,104 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig is Default plus the settings Validate requires.
+func validConfig() *Config {
+	cfg := Default()
+	cfg.DB.User = "postgres"
+	cfg.DB.Name = "pdf_management"
+	cfg.JWT.Secret = strings.Repeat("s", 32)
+	return cfg
+}
+
+func TestLoadReadsFeatureSettings(t *testing.T) {
+	t.Setenv("LOGIN_LOCKOUT_BASE", "2m")
+	t.Setenv("LOGIN_ATTEMPT_STORE", "postgres")
+	t.Setenv("SMTP_PORT", "2525")
+	t.Setenv("OIDC_SCOPES", "openid email")
+	t.Setenv("OIDC_ROLE_MAPPING", "pdf-admins=Admin, pdf-staff=Staff")
+	t.Setenv("SCANNER", "clamd")
+	t.Setenv("LOG_FORMAT", "text")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LoginGuard.LockoutBase != 2*time.Minute || cfg.LoginGuard.Store != "postgres" {
+		t.Errorf("login guard = %+v", cfg.LoginGuard)
+	}
+	if cfg.Mail.SMTPPort != 2525 {
+		t.Errorf("SMTP port = %d, want 2525", cfg.Mail.SMTPPort)
+	}
+	if !slices.Equal(cfg.OIDC.Scopes, []string{"openid", "email"}) {
+		t.Errorf("scopes = %q", cfg.OIDC.Scopes)
+	}
+	want := []OIDCRoleMapping{{Group: "pdf-admins", Role: "Admin"}, {Group: "pdf-staff", Role: "Staff"}}
+	if !slices.Equal(cfg.OIDC.RoleMapping, want) {
+		t.Errorf("role mapping = %+v, want %+v", cfg.OIDC.RoleMapping, want)
+	}
+	if cfg.Scanner.Name != "clamd" || cfg.Log.Format != "text" {
+		t.Errorf("scanner = %q, log format = %q", cfg.Scanner.Name, cfg.Log.Format)
+	}
+}
+
+func TestLoadRejectsInvalidEnv(t *testing.T) {
+	for key, value := range map[string]string{
+		"LOGIN_LOCKOUT_BASE": "abc",
+		"LOGIN_MAX_ATTEMPTS": "five",
+		"SMTP_PORT":          "smtp",
+		"OIDC_ROLE_MAPPING":  "pdf-admins=Admin,pdf-staff",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), key) {
+				t.Fatalf("Load() error = %v, want one naming %s", err, key)
+			}
+		})
+	}
+}
+
+func TestValidateFeatureSettings(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"admin without password", func(c *Config) { c.Admin.Email = "admin@example.com" }, "ADMIN_PASSWORD"},
+		{"oidc without client", func(c *Config) { c.OIDC.Issuer = "https://idp.example.com" }, "OIDC_CLIENT_ID"},
+		{"oidc without openid scope", func(c *Config) {
+			c.OIDC.Issuer, c.OIDC.ClientID, c.OIDC.Scopes = "https://idp.example.com", "pdfms", []string{"email"}
+		}, "OIDC_SCOPES"},
+		{"unknown attempt store", func(c *Config) { c.LoginGuard.Store = "redis" }, "LOGIN_ATTEMPT_STORE"},
+		{"zero max attempts", func(c *Config) { c.LoginGuard.MaxAttempts = 0 }, "LOGIN_MAX_ATTEMPTS"},
+		{"lockout max below base", func(c *Config) { c.LoginGuard.LockoutMax = time.Second }, "LOGIN_LOCKOUT_MAX"},
+		{"smtp without host", func(c *Config) { c.Mail.Mailer = "smtp" }, "SMTP_HOST"},
+		{"unknown mailer", func(c *Config) { c.Mail.Mailer = "sendmail" }, "MAILER"},
+		{"unknown scanner", func(c *Config) { c.Scanner.Name = "virustotal" }, "SCANNER"},
+		{"clamd address without scheme", func(c *Config) {
+			c.Scanner.Name, c.Scanner.ClamdAddress = "clamd", "127.0.0.1:3310"
+		}, "CLAMD_ADDRESS"},
+		{"unknown renderer", func(c *Config) { c.Preview.Renderer = "/usr/bin/gs" }, "PREVIEW_RENDERER"},
+		{"unknown log level", func(c *Config) { c.Log.Level = "verbose" }, "LOG_LEVEL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() error = %v, want one naming %s", err, tt.want)
+			}
+		})
+	}
+}
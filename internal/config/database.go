@@ -1,54 +1,63 @@
 package config
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
+	"strings"
+	"time"
 
 	_ "github.com/lib/pq"
 )
 
-var DB *sql.DB
+const maxConnectBackoff = 30 * time.Second
 
-func ConnectDB() {
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbname := os.Getenv("DB_NAME")
-
-	// Fallback/Default for development if not set, slightly dangerous but helpful for local test if they forget .env
-	if host == "" {
-		host = "localhost"
-	}
-	if port == "" {
-		port = "5432"
-	}
-	if user == "" {
-		user = "postgres"
-	}
-	if dbname == "" {
-		dbname = "pdf_management"
-	}
-	// Password usually must be set, but let's see.
-
-	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname)
-
-	var err error
-	DB, err = sql.Open("postgres", psqlInfo)
+// ConnectDB opens the pool and pings it, retrying with exponential backoff
+// up to cfg.ConnectAttempts times or until ctx is cancelled.
+func ConnectDB(ctx context.Context, cfg DBConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.DSN())
 	if err != nil {
-		log.Fatalf("Error opening database connection: %v", err)
+		return nil, fmt.Errorf("opening database connection: %w", err)
 	}
 
-	err = DB.Ping()
-	if err != nil {
-		log.Printf("Warning: Could not connect to database: %v. Make sure DB is running and credentials are correct.", err)
-		// We don't Fatal here to allow main to run and maybe print help, but for a real backend we might want to fail.
-		// Actually, let's Fatal because per requirements "Connection to DB" is critical.
-		log.Fatal(err)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	// DB bisa belum siap saat container start bersamaan, coba ulang dengan backoff
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err = db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			break
+		}
+		if attempt >= cfg.ConnectAttempts || ctx.Err() != nil {
+			db.Close()
+			return nil, fmt.Errorf("could not connect to database %s at %s:%d after %d attempts, make sure DB is running and credentials are correct: %w",
+				cfg.Name, cfg.Host, cfg.Port, attempt, err)
+		}
+		slog.Warn("database not reachable, retrying",
+			"host", cfg.Host, "port", cfg.Port, "attempt", attempt, "retry_in", delay.String(), "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+		delay = min(delay*2, maxConnectBackoff)
 	}
 
-	log.Println("Successfully connected to the database")
+	slog.Info("connected to the database", "host", cfg.Host, "dbname", cfg.Name, "sslmode", cfg.SSLMode)
+	return db, nil
+}
+
+// DSN returns the lib/pq connection string, quoting values so passwords may contain spaces or quotes.
+func (c DBConfig) DSN() string {
+	quote := func(v string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+	}
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		quote(c.Host), c.Port, quote(c.User), quote(c.Password), quote(c.Name), c.SSLMode)
 }
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package fsutil
+
+// DiskUsage is not supported on this platform.
+func DiskUsage(dir string) (free, total uint64, err error) {
+	return 0, 0, ErrUnsupported
+}
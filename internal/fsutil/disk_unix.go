@@ -0,0 +1,15 @@
+//go:build unix
+
+package fsutil
+
+import "syscall"
+
+// DiskUsage returns the bytes available to unprivileged users and the total
+// size of the filesystem holding dir.
+func DiskUsage(dir string) (free, total uint64, err error) {
+	var s syscall.Statfs_t
+	if err := syscall.Statfs(dir, &s); err != nil {
+		return 0, 0, err
+	}
+	return uint64(s.Bavail) * uint64(s.Bsize), uint64(s.Blocks) * uint64(s.Bsize), nil
+}
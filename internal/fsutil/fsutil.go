@@ -0,0 +1,6 @@
+// Package fsutil has filesystem helpers shared by metrics and health checks.
+package fsutil
+
+import "errors"
+
+var ErrUnsupported = errors.New("disk usage is not supported on this platform")
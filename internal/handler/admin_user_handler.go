@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"pdf-management-system/internal/middleware"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/service"
+	"strconv"
+	"strings"
+)
+
+type AdminUserHandler struct {
+	Service *service.UserService
+}
+
+func NewAdminUserHandler(service *service.UserService) *AdminUserHandler {
+	return &AdminUserHandler{Service: service}
+}
+
+// Users serves GET/POST /api/admin/users
+func (h *AdminUserHandler) Users(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w, r)
+	case http.MethodPost:
+		h.create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// User serves GET/PATCH/DELETE /api/admin/users/{id} and POST /api/admin/users/{id}/unlock
+func (h *AdminUserHandler) User(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/admin/users/"), "/"), "/")
+	if len(parts) > 2 || (len(parts) == 2 && parts[1] != "unlock") {
+		http.NotFound(w, r)
+		return
+	}
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ID", "")
+		return
+	}
+	middleware.AddAuditTarget(r, model.AuditTargetUser, id)
+	if len(parts) == 2 {
+		h.unlock(w, r, id)
+		return
+	}
+
+	actorID, _ := middleware.UserIDFromContext(r.Context())
+
+	var user *model.User
+	switch r.Method {
+	case http.MethodGet:
+		user, err = h.Service.GetUser(r.Context(), id)
+	case http.MethodPatch:
+		var req model.UpdateUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body", "")
+			return
+		}
+		user, err = h.Service.UpdateUser(r.Context(), id, req, actorID)
+	case http.MethodDelete:
+		// Deactivate, not a hard delete: users are referenced by created_by/modified_by
+		user, err = h.Service.DeactivateUser(r.Context(), id, actorID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		respondUserError(w, err)
+		return
+	}
+
+	msg := "User retrieved successfully"
+	switch r.Method {
+	case http.MethodPatch:
+		msg = "User updated successfully"
+	case http.MethodDelete:
+		msg = "User deactivated successfully"
+	}
+	respondSuccess(w, msg, user)
+}
+
+func (h *AdminUserHandler) unlock(w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Body opsional
+	var req model.UnlockRequest
+	json.NewDecoder(r.Body).Decode(&req)
+	if req.IPAddress != "" {
+		middleware.SetAuditDetail(r, "ip_address="+req.IPAddress)
+	}
+
+	user, err := h.Service.UnlockLogin(r.Context(), id, req.IPAddress, clientInfo(r))
+	if err != nil {
+		respondUserError(w, err)
+		return
+	}
+	respondSuccess(w, "Login lockout cleared", user)
+}
+
+// Roles serves GET /api/admin/roles
+func (h *AdminUserHandler) Roles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	roles, err := h.Service.ListRoles(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+		return
+	}
+	respondSuccess(w, "Roles retrieved successfully", roles)
+}
+
+func (h *AdminUserHandler) list(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	var active *bool
+	if v := r.URL.Query().Get("active"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid active filter", "")
+			return
+		}
+		active = &b
+	}
+
+	users, total, err := h.Service.ListUsers(r.Context(), active, page, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+		return
+	}
+
+	if limit <= 0 {
+		limit = 10
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.PaginatedResponse{
+		Success: true,
+		Data:    users,
+		Pagination: model.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+func (h *AdminUserHandler) create(w http.ResponseWriter, r *http.Request) {
+	actorID, _ := middleware.UserIDFromContext(r.Context())
+
+	var req model.CreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", "")
+		return
+	}
+
+	user, err := h.Service.CreateUser(r.Context(), req, actorID)
+	if err != nil {
+		respondUserError(w, err)
+		return
+	}
+	middleware.AddAuditTarget(r, model.AuditTargetUser, user.ID)
+
+	respondSuccess(w, "User created successfully", user)
+}
+
+func respondUserError(w http.ResponseWriter, err error) {
+	if respondValidationError(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		respondError(w, http.StatusNotFound, err.Error(), "USER_NOT_FOUND")
+	case errors.Is(err, service.ErrCannotModifySelf), errors.Is(err, service.ErrLastAdmin):
+		respondError(w, http.StatusConflict, err.Error(), "ADMIN_REQUIRED")
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+	}
+}
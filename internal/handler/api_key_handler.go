@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"pdf-management-system/internal/middleware"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/service"
+	"strconv"
+	"strings"
+)
+
+type APIKeyHandler struct {
+	Service *service.APIKeyService
+}
+
+func NewAPIKeyHandler(service *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{Service: service}
+}
+
+// Keys serves GET/POST /api/keys for the current user.
+func (h *APIKeyHandler) Keys(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.UserIDFromContext(r.Context())
+
+	switch r.Method {
+	case http.MethodGet:
+		keys, err := h.Service.List(r.Context(), userID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error(), "")
+			return
+		}
+		respondSuccess(w, "API keys retrieved successfully", keys)
+	case http.MethodPost:
+		middleware.AddAuditTarget(r, model.AuditTargetUser, userID)
+		var req model.CreateAPIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body", "")
+			return
+		}
+		key, err := h.Service.Create(r.Context(), userID, req)
+		if respondValidationError(w, err) {
+			return
+		}
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error(), "")
+			return
+		}
+		middleware.SetAuditDetail(r, fmt.Sprintf("api_key_id=%d", key.ID))
+		respondSuccess(w, "API key created, store it now: it will not be shown again", key)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Key serves DELETE /api/keys/{id}
+func (h *APIKeyHandler) Key(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/keys/"), "/"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ID", "")
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	middleware.AddAuditTarget(r, model.AuditTargetUser, userID)
+	middleware.SetAuditDetail(r, fmt.Sprintf("api_key_id=%d", id))
+
+	key, err := h.Service.Revoke(r.Context(), userID, id)
+	if errors.Is(err, service.ErrAPIKeyNotFound) {
+		respondError(w, http.StatusNotFound, err.Error(), "API_KEY_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+		return
+	}
+
+	respondSuccess(w, "API key revoked", key)
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/service"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AuditHandler struct {
+	Service *service.AuditService
+}
+
+func NewAuditHandler(service *service.AuditService) *AuditHandler {
+	return &AuditHandler{Service: service}
+}
+
+// Events serves GET /api/admin/audit, as JSON or as CSV with ?format=csv
+func (h *AuditHandler) Events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error(), "INVALID_FILTER")
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		h.exportCSV(w, r, filter)
+		return
+	}
+
+	events, total, filter, err := h.Service.List(r.Context(), filter)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.PaginatedResponse{
+		Success: true,
+		Data:    events,
+		Pagination: model.Pagination{
+			Page:  filter.Page,
+			Limit: filter.Limit,
+			Total: total,
+		},
+	})
+}
+
+func (h *AuditHandler) exportCSV(w http.ResponseWriter, r *http.Request, filter model.AuditFilter) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-%s.csv"`, time.Now().Format("20060102-150405")))
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "created_at", "actor_id", "action", "target_type", "target_id", "outcome", "status_code", "ip_address", "user_agent", "detail"})
+	err := h.Service.Export(r.Context(), filter, func(e *model.AuditEvent) error {
+		return cw.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.CreatedAt.Format(time.RFC3339),
+			optionalID(e.ActorID),
+			e.Action,
+			e.TargetType,
+			optionalID(e.TargetID),
+			e.Outcome,
+			strconv.Itoa(e.StatusCode),
+			csvSafe(e.IPAddress),
+			csvSafe(e.UserAgent),
+			csvSafe(e.Detail),
+		})
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	if err != nil {
+		// Header sudah terkirim, hanya bisa dicatat
+		slog.ErrorContext(r.Context(), "audit CSV export failed", "error", err)
+	}
+}
+
+func parseAuditFilter(r *http.Request) (model.AuditFilter, error) {
+	q := r.URL.Query()
+	f := model.AuditFilter{
+		Action:     q.Get("action"),
+		TargetType: q.Get("target_type"),
+		Outcome:    strings.ToUpper(q.Get("outcome")),
+	}
+	f.Page, _ = strconv.Atoi(q.Get("page"))
+	f.Limit, _ = strconv.Atoi(q.Get("limit"))
+
+	var err error
+	if f.ActorID, err = parseOptionalID(q.Get("actor_id")); err != nil {
+		return f, fmt.Errorf("invalid actor_id")
+	}
+	if f.TargetID, err = parseOptionalID(q.Get("target_id")); err != nil {
+		return f, fmt.Errorf("invalid target_id")
+	}
+	if f.Outcome != "" && f.Outcome != model.AuditSuccess && f.Outcome != model.AuditFailure {
+		return f, fmt.Errorf("outcome must be %s or %s", model.AuditSuccess, model.AuditFailure)
+	}
+	if f.From, err = parseAuditTime(q.Get("from"), false); err != nil {
+		return f, fmt.Errorf("invalid from, use RFC3339 or YYYY-MM-DD")
+	}
+	if f.To, err = parseAuditTime(q.Get("to"), true); err != nil {
+		return f, fmt.Errorf("invalid to, use RFC3339 or YYYY-MM-DD")
+	}
+	return f, nil
+}
+
+func parseOptionalID(v string) (*int64, error) {
+	if v == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// parseAuditTime accepts RFC3339 or a date; a date in "to" includes that whole day.
+func parseAuditTime(v string, endOfDay bool) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func optionalID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+// csvSafe stops spreadsheet apps from running user-controlled values as formulas.
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
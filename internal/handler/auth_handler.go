@@ -2,9 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"pdf-management-system/internal/middleware"
 	"pdf-management-system/internal/model"
 	"pdf-management-system/internal/service"
+	"strconv"
 )
 
 type AuthHandler struct {
@@ -27,11 +32,20 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.Service.Register(req)
+	user, err := h.Service.Register(r.Context(), req)
+	if respondValidationError(w, err) {
+		return
+	}
+	if errors.Is(err, service.ErrDefaultRoleNotFound) {
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+		return
+	}
 	if err != nil {
 		respondError(w, http.StatusBadRequest, err.Error(), "REGISTRATION_FAILED")
 		return
 	}
+	middleware.SetAuditActor(r, user.ID)
+	middleware.AddAuditTarget(r, model.AuditTargetUser, user.ID)
 
 	respondSuccess(w, "User registered successfully", user)
 }
@@ -43,16 +57,262 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req model.LoginRequest
+	r.Body = http.MaxBytesReader(w, r.Body, 4<<10)
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid request body", "")
 		return
 	}
 
-	resp, err := h.Service.Login(req)
+	middleware.SetAuditDetail(r, req.Email)
+	resp, err := h.Service.Login(r.Context(), req, clientInfo(r))
+	if respondValidationError(w, err) {
+		return
+	}
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		respondError(w, http.StatusForbidden, err.Error(), "EMAIL_NOT_VERIFIED")
+		return
+	}
+	var locked *service.LoginLockedError
+	if errors.As(err, &locked) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		respondError(w, http.StatusTooManyRequests, err.Error(), "LOGIN_LOCKED")
+		return
+	}
+	if errors.Is(err, service.ErrAccountDisabled) {
+		respondError(w, http.StatusForbidden, err.Error(), "ACCOUNT_DISABLED")
+		return
+	}
 	if err != nil {
 		respondError(w, http.StatusUnauthorized, err.Error(), "LOGIN_FAILED")
 		return
 	}
+	middleware.SetAuditActor(r, resp.User.ID)
+	middleware.AddAuditTarget(r, model.AuditTargetUser, resp.User.ID)
 
 	respondSuccess(w, "Login successful", resp)
 }
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		respondError(w, http.StatusBadRequest, "Invalid request body", "")
+		return
+	}
+
+	resp, err := h.Service.Refresh(r.Context(), req.RefreshToken, clientInfo(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			respondError(w, http.StatusUnauthorized, err.Error(), "REFRESH_TOKEN_REUSED")
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			respondError(w, http.StatusUnauthorized, err.Error(), "INVALID_REFRESH_TOKEN")
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error(), "")
+		}
+		return
+	}
+	middleware.SetAuditActor(r, resp.User.ID)
+
+	respondSuccess(w, "Token refreshed", resp)
+}
+
+// Logout requires auth; revokes the current access token and the optional refresh token.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized", "")
+		return
+	}
+
+	// Body opsional
+	var req model.LogoutRequest
+	json.NewDecoder(r.Body).Decode(&req)
+
+	if err := h.Service.Logout(r.Context(), claims, req.RefreshToken); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+		return
+	}
+
+	respondSuccess(w, "Logged out", nil)
+}
+
+// LogoutAll requires auth; revokes every session of the current user.
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized", "")
+		return
+	}
+
+	if err := h.Service.LogoutAll(r.Context(), userID); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+		return
+	}
+
+	respondSuccess(w, "Logged out from all sessions", nil)
+}
+
+// VerifyEmail serves GET /api/auth/verify?token=
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := h.Service.VerifyEmail(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidVerifyToken) {
+			respondError(w, http.StatusBadRequest, err.Error(), "INVALID_VERIFICATION_TOKEN")
+		} else {
+			respondError(w, http.StatusInternalServerError, err.Error(), "")
+		}
+		return
+	}
+	middleware.SetAuditActor(r, user.ID)
+	middleware.AddAuditTarget(r, model.AuditTargetUser, user.ID)
+
+	respondSuccess(w, "Email verified successfully", user)
+}
+
+// ResendVerification serves POST /api/auth/verify/resend
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.ResendVerificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		respondError(w, http.StatusBadRequest, "Invalid request body", "")
+		return
+	}
+
+	middleware.SetAuditDetail(r, req.Email)
+	if err := h.Service.ResendVerification(r.Context(), req.Email); err != nil {
+		if errors.Is(err, service.ErrVerificationRateLimited) {
+			respondError(w, http.StatusTooManyRequests, err.Error(), "RATE_LIMITED")
+		} else {
+			respondError(w, http.StatusInternalServerError, "Failed to send verification email", "")
+		}
+		return
+	}
+
+	// Respons sama untuk email terdaftar maupun tidak
+	respondSuccess(w, "If the account exists and is unverified, a verification email has been sent", nil)
+}
+
+// ForgotPassword serves POST /api/auth/forgot-password
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		respondError(w, http.StatusBadRequest, "Invalid request body", "")
+		return
+	}
+
+	middleware.SetAuditDetail(r, req.Email)
+	if err := h.Service.ForgotPassword(r.Context(), req.Email, clientInfo(r)); err != nil {
+		if errors.Is(err, service.ErrResetRateLimited) {
+			respondError(w, http.StatusTooManyRequests, err.Error(), "RATE_LIMITED")
+		} else {
+			respondError(w, http.StatusInternalServerError, "Failed to send reset email", "")
+		}
+		return
+	}
+
+	// Respons sama untuk email terdaftar maupun tidak
+	respondSuccess(w, "If the account exists, a password reset email has been sent", nil)
+}
+
+// ResetPassword serves POST /api/auth/reset-password
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		respondError(w, http.StatusBadRequest, "Invalid request body", "")
+		return
+	}
+
+	if err := h.Service.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		respondPasswordError(w, err)
+		return
+	}
+
+	respondSuccess(w, "Password has been reset, please log in again", nil)
+}
+
+// ChangePassword serves POST /api/auth/change-password (requires auth)
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized", "")
+		return
+	}
+
+	var req model.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", "")
+		return
+	}
+
+	middleware.AddAuditTarget(r, model.AuditTargetUser, userID)
+	resp, err := h.Service.ChangePassword(r.Context(), userID, req, clientInfo(r))
+	if err != nil {
+		respondPasswordError(w, err)
+		return
+	}
+
+	respondSuccess(w, "Password changed, other sessions have been logged out", resp)
+}
+
+func respondPasswordError(w http.ResponseWriter, err error) {
+	if respondValidationError(w, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrInvalidResetToken):
+		respondError(w, http.StatusBadRequest, err.Error(), "INVALID_RESET_TOKEN")
+	case errors.Is(err, service.ErrWrongPassword):
+		respondError(w, http.StatusBadRequest, err.Error(), "WRONG_PASSWORD")
+	case errors.Is(err, service.ErrPasswordUnchanged):
+		respondError(w, http.StatusBadRequest, err.Error(), "WEAK_PASSWORD")
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+	}
+}
+
+func clientInfo(r *http.Request) model.ClientInfo {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return model.ClientInfo{IP: ip, UserAgent: r.UserAgent()}
+}
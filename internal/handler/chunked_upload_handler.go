@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"pdf-management-system/internal/middleware"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/service"
+	"strconv"
+	"strings"
+)
+
+type ChunkedUploadHandler struct {
+	Service *service.ChunkedUploadService
+}
+
+func NewChunkedUploadHandler(service *service.ChunkedUploadService) *ChunkedUploadHandler {
+	return &ChunkedUploadHandler{Service: service}
+}
+
+// Init serves POST /api/pdf/uploads
+func (h *ChunkedUploadHandler) Init(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	roleID, _ := middleware.RoleIDFromContext(r.Context())
+
+	var req model.InitUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", "")
+		return
+	}
+
+	sess, err := h.Service.Init(r.Context(), userID, roleID, req)
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+
+	respondSuccess(w, "Upload session created", sess)
+}
+
+// Session serves /api/pdf/uploads/{id}[/chunks|/complete]
+func (h *ChunkedUploadHandler) Session(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/pdf/uploads/"), "/"), "/")
+	id := parts[0]
+	if id == "" || len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.status(w, r, id)
+	case action == "" && r.Method == http.MethodDelete:
+		h.abort(w, r, id)
+	case action == "chunks" && r.Method == http.MethodPut:
+		h.writeChunk(w, r, id)
+	case action == "complete" && r.Method == http.MethodPost:
+		h.complete(w, r, id)
+	case action == "" || action == "chunks" || action == "complete":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *ChunkedUploadHandler) status(w http.ResponseWriter, r *http.Request, id string) {
+	userID, _ := middleware.UserIDFromContext(r.Context())
+
+	sess, err := h.Service.Status(r.Context(), userID, id)
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(sess.Offset, 10))
+	respondSuccess(w, "Upload session found", sess)
+}
+
+func (h *ChunkedUploadHandler) abort(w http.ResponseWriter, r *http.Request, id string) {
+	userID, _ := middleware.UserIDFromContext(r.Context())
+
+	if err := h.Service.Abort(r.Context(), userID, id); err != nil {
+		respondUploadError(w, err)
+		return
+	}
+
+	respondSuccess(w, "Upload aborted", nil)
+}
+
+// writeChunk expects the chunk bytes as the raw body, its starting offset in the
+// Upload-Offset header (or ?offset=) and an optional X-Chunk-Checksum (sha256 hex).
+func (h *ChunkedUploadHandler) writeChunk(w http.ResponseWriter, r *http.Request, id string) {
+	userID, _ := middleware.UserIDFromContext(r.Context())
+
+	offsetStr := r.Header.Get("Upload-Offset")
+	if offsetStr == "" {
+		offsetStr = r.URL.Query().Get("offset")
+	}
+	offset, err := strconv.ParseInt(offsetStr, 10, 64)
+	if err != nil || offset < 0 {
+		respondError(w, http.StatusBadRequest, "Invalid or missing Upload-Offset", "INVALID_OFFSET")
+		return
+	}
+
+	// Chunk yang melebihi batas dipotong di service, ini hanya pengaman tambahan
+	r.Body = http.MaxBytesReader(w, r.Body, h.Service.ChunkSize+1)
+
+	sess, err := h.Service.WriteChunk(r.Context(), userID, id, offset, r.Header.Get("X-Chunk-Checksum"), r.Body)
+	if errors.Is(err, service.ErrOffsetMismatch) {
+		w.Header().Set("Upload-Offset", strconv.FormatInt(sess.Offset, 10))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(model.ApiResponse{
+			Success:   false,
+			Message:   err.Error(),
+			Data:      sess,
+			ErrorCode: "OFFSET_MISMATCH",
+			RequestID: w.Header().Get(middleware.RequestIDHeader),
+		})
+		return
+	}
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(sess.Offset, 10))
+	respondSuccess(w, "Chunk stored", sess)
+}
+
+func (h *ChunkedUploadHandler) complete(w http.ResponseWriter, r *http.Request, id string) {
+	userID, _ := middleware.UserIDFromContext(r.Context())
+
+	pdf, err := h.Service.Complete(r.Context(), userID, id)
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+	middleware.AddAuditTarget(r, model.AuditTargetPdf, pdf.ID)
+
+	respondSuccess(w, "PDF uploaded successfully", pdf)
+}
+
+func respondUploadError(w http.ResponseWriter, err error) {
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, service.ErrUploadNotFound):
+		respondError(w, http.StatusNotFound, err.Error(), "UPLOAD_NOT_FOUND")
+	case errors.Is(err, service.ErrUploadNotActive):
+		respondError(w, http.StatusGone, err.Error(), "UPLOAD_NOT_ACTIVE")
+	case errors.Is(err, service.ErrUploadTooLarge):
+		respondError(w, http.StatusRequestEntityTooLarge, err.Error(), "FILE_TOO_LARGE")
+	case errors.Is(err, service.ErrChunkTooLarge), errors.As(err, &maxErr):
+		respondError(w, http.StatusRequestEntityTooLarge, service.ErrChunkTooLarge.Error(), "CHUNK_TOO_LARGE")
+	case errors.Is(err, service.ErrEmptyChunk):
+		respondError(w, http.StatusBadRequest, err.Error(), "EMPTY_CHUNK")
+	case errors.Is(err, service.ErrChecksumMismatch):
+		respondError(w, http.StatusBadRequest, err.Error(), "CHECKSUM_MISMATCH")
+	case errors.Is(err, service.ErrUploadIncomplete):
+		respondError(w, http.StatusConflict, err.Error(), "UPLOAD_INCOMPLETE")
+	case errors.Is(err, service.ErrInvalidUploadName), errors.Is(err, service.ErrNotPdfContent):
+		respondError(w, http.StatusBadRequest, err.Error(), "INVALID_FILE_TYPE")
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+	}
+}
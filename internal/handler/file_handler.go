@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"pdf-management-system/internal/middleware"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/service"
+	"strings"
+)
+
+// FileHandler serves stored PDFs under /uploads/pdf/, refusing files that
+// are still pending a malware scan or have been quarantined.
+type FileHandler struct {
+	Service *service.PdfService
+}
+
+func NewFileHandler(service *service.PdfService) *FileHandler {
+	return &FileHandler{Service: service}
+}
+
+func (h *FileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filename := strings.TrimPrefix(r.URL.Path, "/uploads/pdf/")
+	if filename == r.URL.Path || filename == "" || strings.ContainsAny(filename, `/\`) || strings.HasPrefix(filename, ".") {
+		http.NotFound(w, r)
+		return
+	}
+
+	pdf, path, err := h.Service.DownloadPath(r.Context(), filename)
+	if pdf != nil {
+		middleware.AddAuditTarget(r, model.AuditTargetPdf, pdf.ID)
+	} else {
+		middleware.SetAuditDetail(r, filename)
+	}
+	if err != nil {
+		switch {
+		case err.Error() == "file not found":
+			http.NotFound(w, r)
+		case errors.Is(err, service.ErrFileNotClean):
+			respondError(w, http.StatusForbidden, "File is pending malware scan or quarantined", "FILE_NOT_CLEAN")
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error(), "")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	http.ServeFile(w, r, path)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/service"
+	"testing"
+)
+
+// filenameStore serves FindByFilename from a map; FileHandler needs nothing else.
+type filenameStore struct {
+	service.PdfStore
+	files map[string]*model.PdfFile
+}
+
+func (s filenameStore) FindByFilename(ctx context.Context, filename string) (*model.PdfFile, error) {
+	pdf, ok := s.files[filename]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return pdf, nil
+}
+
+func TestFileHandlerRefusesUncleanFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]*model.PdfFile{
+		"pending.pdf":     {ID: 1, Filename: "pending.pdf", Status: model.StatusPendingScan},
+		"quarantined.pdf": {ID: 2, Filename: "quarantined.pdf", Status: model.StatusQuarantined},
+		"clean.pdf":       {ID: 3, Filename: "clean.pdf", Status: model.StatusUploaded},
+	}
+	// Isi disk juga untuk file yang ditolak: penolakan harus dari status, bukan dari file yang hilang
+	for name := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("%PDF-1.4"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := NewFileHandler(&service.PdfService{Repo: filenameStore{files: files}, Dir: dir})
+
+	tests := []struct {
+		file      string
+		status    int
+		errorCode string
+	}{
+		{file: "pending.pdf", status: http.StatusForbidden, errorCode: "FILE_NOT_CLEAN"},
+		{file: "quarantined.pdf", status: http.StatusForbidden, errorCode: "FILE_NOT_CLEAN"},
+		{file: "clean.pdf", status: http.StatusOK},
+		{file: "unknown.pdf", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/uploads/pdf/"+tt.file, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.errorCode != "" {
+				var body model.ApiResponse
+				if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				if body.ErrorCode != tt.errorCode {
+					t.Errorf("error_code = %q, want %q", body.ErrorCode, tt.errorCode)
+				}
+			}
+		})
+	}
+}
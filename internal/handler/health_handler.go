@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"pdf-management-system/internal/health"
+	"pdf-management-system/internal/model"
+)
+
+type HealthHandler struct {
+	Checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{Checker: checker}
+}
+
+// Healthz serves GET /healthz: the process is up and serving requests.
+// Dependencies are not checked so a DB outage does not restart the pod.
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	respondSuccess(w, "ok", nil)
+}
+
+// Readyz serves GET /readyz: 200 when every check passes, otherwise 503 with
+// the result of each check.
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ready, checks := h.Checker.Run(r.Context())
+	resp := model.ApiResponse{Success: ready, Message: "ready", Data: checks}
+	status := http.StatusOK
+	if !ready {
+		resp.Message, resp.ErrorCode = "not ready", "NOT_READY"
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"pdf-management-system/internal/middleware"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/service"
+)
+
+type ImportHandler struct {
+	Service *service.ImportService
+}
+
+func NewImportHandler(service *service.ImportService) *ImportHandler {
+	return &ImportHandler{Service: service}
+}
+
+// ImportPDF serves POST /api/pdf/import
+func (h *ImportHandler) ImportPDF(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req model.ImportPdfRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.URL == "" {
+		respondError(w, http.StatusBadRequest, "Invalid request body", "")
+		return
+	}
+
+	middleware.SetAuditDetail(r, req.URL)
+	pdf, err := h.Service.Import(r.Context(), req.URL)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidImportURL):
+			respondError(w, http.StatusBadRequest, err.Error(), "INVALID_URL")
+		case errors.Is(err, service.ErrImportBlocked):
+			respondError(w, http.StatusBadRequest, err.Error(), "URL_NOT_ALLOWED")
+		case errors.Is(err, service.ErrImportTooLarge):
+			respondError(w, http.StatusBadRequest, err.Error(), "FILE_TOO_LARGE")
+		case errors.Is(err, service.ErrNotPdfContent):
+			respondError(w, http.StatusBadRequest, err.Error(), "INVALID_FILE_TYPE")
+		case errors.Is(err, service.ErrImportFetch):
+			respondError(w, http.StatusBadGateway, err.Error(), "FETCH_FAILED")
+		default:
+			respondError(w, http.StatusInternalServerError, err.Error(), "")
+		}
+		return
+	}
+	middleware.AddAuditTarget(r, model.AuditTargetPdf, pdf.ID)
+
+	respondSuccess(w, "PDF imported successfully", pdf)
+}
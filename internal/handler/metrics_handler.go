@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"pdf-management-system/internal/metrics"
+)
+
+// MetricsHandler serves GET /metrics in the Prometheus text format. When
+// Token is set, scrapers must send "Authorization: Bearer <Token>".
+type MetricsHandler struct {
+	Registry *metrics.Registry
+	Token    string
+}
+
+func NewMetricsHandler(registry *metrics.Registry, token string) *MetricsHandler {
+	return &MetricsHandler{Registry: registry, Token: token}
+}
+
+func (h *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.Token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+h.Token)) != 1 {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := h.Registry.Write(w); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write metrics", "error", err)
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"pdf-management-system/internal/middleware"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/oidc"
+	"pdf-management-system/internal/service"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const oidcStateCookie = "oidc_state"
+
+type OIDCHandler struct {
+	Service *service.OIDCService
+}
+
+func NewOIDCHandler(service *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{Service: service}
+}
+
+// Login serves GET /api/auth/oidc/login and redirects to the identity provider.
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.Service == nil {
+		respondError(w, http.StatusNotFound, service.ErrOIDCDisabled.Error(), "OIDC_DISABLED")
+		return
+	}
+
+	redirectURL, state, err := h.Service.Begin(r.Context())
+	if err != nil {
+		respondError(w, http.StatusBadGateway, err.Error(), "OIDC_UNAVAILABLE")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/auth/oidc",
+		MaxAge:   int((10 * time.Minute).Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.Service.Config.RedirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+// Callback serves GET /api/auth/oidc/callback. It returns the session as JSON,
+// or redirects to OIDC_POST_LOGIN_REDIRECT with the tokens in the URL fragment.
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.Service == nil {
+		respondError(w, http.StatusNotFound, service.ErrOIDCDisabled.Error(), "OIDC_DISABLED")
+		return
+	}
+
+	// State hanya boleh dipakai sekali
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Value: "", Path: "/api/auth/oidc", MaxAge: -1, HttpOnly: true})
+
+	q := r.URL.Query()
+	if idpErr := q.Get("error"); idpErr != "" {
+		respondError(w, http.StatusUnauthorized, "Identity provider returned "+idpErr+": "+q.Get("error_description"), "OIDC_LOGIN_FAILED")
+		return
+	}
+
+	cookieState := ""
+	if c, err := r.Cookie(oidcStateCookie); err == nil {
+		cookieState = c.Value
+	}
+
+	resp, err := h.Service.Complete(r.Context(), q.Get("code"), q.Get("state"), cookieState, clientInfo(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCState):
+			respondError(w, http.StatusBadRequest, err.Error(), "OIDC_STATE_MISMATCH")
+		case errors.Is(err, service.ErrAccountDisabled):
+			respondError(w, http.StatusForbidden, err.Error(), "ACCOUNT_DISABLED")
+		case errors.Is(err, service.ErrOIDCEmailConflict):
+			respondError(w, http.StatusConflict, err.Error(), "OIDC_EMAIL_CONFLICT")
+		case errors.Is(err, oidc.ErrInvalidIDToken), errors.Is(err, service.ErrOIDCNoEmail):
+			respondError(w, http.StatusUnauthorized, err.Error(), "OIDC_LOGIN_FAILED")
+		default:
+			respondError(w, http.StatusBadGateway, err.Error(), "OIDC_LOGIN_FAILED")
+		}
+		return
+	}
+	middleware.SetAuditActor(r, resp.User.ID)
+	middleware.AddAuditTarget(r, model.AuditTargetUser, resp.User.ID)
+
+	if target := h.Service.PostLoginRedirect; target != "" {
+		fragment := url.Values{
+			"access_token":  {resp.Token},
+			"refresh_token": {resp.RefreshToken},
+			"expires_in":    {strconv.FormatInt(resp.ExpiresIn, 10)},
+		}
+		http.Redirect(w, r, target+"#"+fragment.Encode(), http.StatusFound)
+		return
+	}
+
+	respondSuccess(w, "Login successful", resp)
+}
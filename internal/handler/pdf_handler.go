@@ -1,20 +1,32 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/middleware"
 	"pdf-management-system/internal/model"
 	"pdf-management-system/internal/service"
+	"pdf-management-system/internal/validation"
 	"strconv"
 	"strings"
 )
 
 type PdfHandler struct {
 	Service *service.PdfService
+
+	// Batas POST /api/pdf/upload
+	MaxFileSize int64
+	MaxFiles    int
 }
 
-func NewPdfHandler(service *service.PdfService) *PdfHandler {
-	return &PdfHandler{Service: service}
+func NewPdfHandler(service *service.PdfService, cfg *config.Config) *PdfHandler {
+	return &PdfHandler{Service: service, MaxFileSize: cfg.Upload.MaxFileSize, MaxFiles: cfg.Upload.MaxFiles}
 }
 
 func (h *PdfHandler) GenerateReport(w http.ResponseWriter, r *http.Request) {
@@ -29,11 +41,15 @@ func (h *PdfHandler) GenerateReport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pdf, err := h.Service.GeneratePDF(req)
+	pdf, err := h.Service.GeneratePDF(r.Context(), req)
+	if respondValidationError(w, err) {
+		return
+	}
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error(), "")
 		return
 	}
+	middleware.AddAuditTarget(r, model.AuditTargetPdf, pdf.ID)
 
 	respondSuccess(w, "PDF generated successfully", pdf)
 }
@@ -44,32 +60,100 @@ func (h *PdfHandler) UploadPDF(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 10MB limit
-	r.Body = http.MaxBytesReader(w, r.Body, 10<<20)
+	// MaxFileSize per file, multiple "file" parts allowed
+	r.Body = http.MaxBytesReader(w, r.Body, int64(h.MaxFiles)*h.MaxFileSize+(1<<20))
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		respondError(w, http.StatusBadRequest, "File size exceeds maximum limit (10MB)", "FILE_TOO_LARGE")
+		respondError(w, http.StatusBadRequest, "Request size exceeds maximum limit", "FILE_TOO_LARGE")
 		return
 	}
+	defer r.MultipartForm.RemoveAll()
 
-	file, header, err := r.FormFile("file") // Field name wasn't specified but usually 'file'
-	if err != nil {
+	headers := r.MultipartForm.File["file"] // Field name wasn't specified but usually 'file'
+	if len(headers) == 0 {
 		respondError(w, http.StatusBadRequest, "Missing file part", "")
 		return
 	}
-	defer file.Close()
+	if len(headers) > h.MaxFiles {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Maximum %d files per request", h.MaxFiles), "TOO_MANY_FILES")
+		return
+	}
 
-	if header.Header.Get("Content-Type") != "application/pdf" && !strings.HasSuffix(header.Filename, ".pdf") {
-		respondError(w, http.StatusBadRequest, "Only PDF files are allowed", "INVALID_FILE_TYPE")
+	// Single file keeps the original response shape
+	if len(headers) == 1 {
+		result, status := h.uploadOne(r.Context(), headers[0])
+		if result.Success {
+			middleware.AddAuditTarget(r, model.AuditTargetPdf, result.Data.ID)
+		}
+		if !result.Success {
+			respondError(w, status, result.Message, result.ErrorCode)
+			return
+		}
+		respondSuccess(w, "PDF uploaded successfully", result.Data)
 		return
 	}
 
-	pdf, err := h.Service.UploadPDF(file, header)
+	results := make([]model.UploadResult, 0, len(headers))
+	uploaded := 0
+	for _, header := range headers {
+		result, _ := h.uploadOne(r.Context(), header)
+		if result.Success {
+			middleware.AddAuditTarget(r, model.AuditTargetPdf, result.Data.ID)
+			uploaded++
+		}
+		results = append(results, result)
+	}
+
+	code := http.StatusOK
+	if uploaded < len(results) {
+		code = http.StatusMultiStatus
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(model.ApiResponse{
+		Success: uploaded == len(results),
+		Message: fmt.Sprintf("%d of %d files uploaded", uploaded, len(results)),
+		Data:    results,
+	})
+}
+
+// uploadOne validates and stores a single multipart file independently of the others.
+func (h *PdfHandler) uploadOne(ctx context.Context, header *multipart.FileHeader) (model.UploadResult, int) {
+	result := model.UploadResult{Filename: header.Filename}
+
+	if header.Header.Get("Content-Type") != "application/pdf" && !strings.HasSuffix(header.Filename, ".pdf") {
+		result.ErrorCode = "INVALID_FILE_TYPE"
+		result.Message = "Only PDF files are allowed"
+		return result, http.StatusBadRequest
+	}
+
+	if header.Size > h.MaxFileSize {
+		result.ErrorCode = "FILE_TOO_LARGE"
+		result.Message = fmt.Sprintf("File size exceeds maximum limit (%s)", formatSize(h.MaxFileSize))
+		return result, http.StatusBadRequest
+	}
+
+	file, err := header.Open()
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error(), "")
-		return
+		result.Message = "Failed to read file part"
+		return result, http.StatusBadRequest
+	}
+	defer file.Close()
+
+	pdf, err := h.Service.UploadPDF(ctx, file, header)
+	if errors.Is(err, service.ErrInvalidUploadName) {
+		result.ErrorCode = "INVALID_FILE_TYPE"
+		result.Message = err.Error()
+		return result, http.StatusBadRequest
+	}
+	if err != nil {
+		result.Message = err.Error()
+		return result, http.StatusInternalServerError
 	}
 
-	respondSuccess(w, "PDF uploaded successfully", pdf)
+	result.Success = true
+	result.Data = pdf
+	return result, http.StatusOK
 }
 
 func (h *PdfHandler) ListPDFs(w http.ResponseWriter, r *http.Request) {
@@ -85,7 +169,7 @@ func (h *PdfHandler) ListPDFs(w http.ResponseWriter, r *http.Request) {
 	page, _ := strconv.Atoi(pageStr)
 	limit, _ := strconv.Atoi(limitStr)
 
-	files, total, err := h.Service.ListPDFs(status, page, limit)
+	files, total, err := h.Service.ListPDFs(r.Context(), status, page, limit)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error(), "")
 		return
@@ -136,8 +220,9 @@ func (h *PdfHandler) DeletePDF(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "Invalid ID", "")
 		return
 	}
+	middleware.AddAuditTarget(r, model.AuditTargetPdf, id)
 
-	pdf, err := h.Service.DeletePDF(id)
+	pdf, err := h.Service.DeletePDF(r.Context(), id)
 	if err != nil {
 		// Distinguish not found vs other errors?
 		if err.Error() == "file not found" {
@@ -163,11 +248,47 @@ func respondSuccess(w http.ResponseWriter, message string, data interface{}) {
 }
 
 func respondError(w http.ResponseWriter, code int, message string, errorCode string) {
+	requestID := w.Header().Get(middleware.RequestIDHeader)
+	if code >= http.StatusInternalServerError {
+		slog.Error("request failed", "request_id", requestID, "status", code, "error_code", errorCode, "error", message)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	json.NewEncoder(w).Encode(model.ApiResponse{
 		Success:   false,
 		Message:   message,
 		ErrorCode: errorCode,
+		RequestID: requestID,
 	})
 }
+
+// respondValidationError writes a 400 VALIDATION_FAILED response with the
+// field errors when err is a validation.Errors. Returns false otherwise.
+func respondValidationError(w http.ResponseWriter, err error) bool {
+	var errs validation.Errors
+	if !errors.As(err, &errs) {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(model.ApiResponse{
+		Success:   false,
+		Message:   "Validation failed",
+		ErrorCode: "VALIDATION_FAILED",
+		Errors:    errs,
+		RequestID: w.Header().Get(middleware.RequestIDHeader),
+	})
+	return true
+}
+
+// formatSize formats a byte limit for error messages, e.g. 10MB or 512KB.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", n>>10)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"pdf-management-system/internal/service"
+	"strconv"
+	"strings"
+)
+
+type PreviewHandler struct {
+	Service *service.PreviewService
+}
+
+func NewPreviewHandler(service *service.PreviewService) *PreviewHandler {
+	return &PreviewHandler{Service: service}
+}
+
+// Thumbnail serves GET /api/pdf/{id}/thumbnail
+func (h *PreviewHandler) Thumbnail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, rest, ok := parsePdfPath(r.URL.Path)
+	if !ok || len(rest) != 1 || rest[0] != "thumbnail" {
+		respondError(w, http.StatusBadRequest, "Invalid URL", "")
+		return
+	}
+
+	path, err := h.Service.Thumbnail(r.Context(), id)
+	if err != nil {
+		respondPreviewError(w, err)
+		return
+	}
+
+	servePNG(w, r, path)
+}
+
+// Page serves GET /api/pdf/{id}/pages/{n}.png?dpi=
+func (h *PreviewHandler) Page(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, rest, ok := parsePdfPath(r.URL.Path)
+	if !ok || len(rest) != 2 || rest[0] != "pages" || !strings.HasSuffix(rest[1], ".png") {
+		respondError(w, http.StatusBadRequest, "Invalid URL", "")
+		return
+	}
+
+	page, err := strconv.Atoi(strings.TrimSuffix(rest[1], ".png"))
+	if err != nil || page < 1 {
+		respondError(w, http.StatusBadRequest, "Invalid page number", "")
+		return
+	}
+
+	dpi := 0
+	if dpiStr := r.URL.Query().Get("dpi"); dpiStr != "" {
+		dpi, err = strconv.Atoi(dpiStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid dpi", "")
+			return
+		}
+	}
+
+	path, err := h.Service.Page(r.Context(), id, page, dpi)
+	if err != nil {
+		respondPreviewError(w, err)
+		return
+	}
+
+	servePNG(w, r, path)
+}
+
+func respondPreviewError(w http.ResponseWriter, err error) {
+	switch {
+	case err.Error() == "file not found":
+		respondError(w, http.StatusNotFound, "File not found", "")
+	case err == service.ErrPageNotFound:
+		respondError(w, http.StatusNotFound, "Page not found", "PAGE_NOT_FOUND")
+	case err == service.ErrInvalidDPI:
+		respondError(w, http.StatusBadRequest, err.Error(), "INVALID_DPI")
+	case err == service.ErrFileNotClean:
+		respondError(w, http.StatusForbidden, err.Error(), "FILE_NOT_CLEAN")
+	case err == service.ErrPreviewUnavailable:
+		respondError(w, http.StatusServiceUnavailable, "Preview rendering is not available", "PREVIEW_UNAVAILABLE")
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+	}
+}
+
+func servePNG(w http.ResponseWriter, r *http.Request, path string) {
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	http.ServeFile(w, r, path)
+}
+
+// parsePdfPath splits /api/pdf/{id}/rest... into the ID and the remaining segments.
+func parsePdfPath(path string) (int64, []string, bool) {
+	trimmed := strings.Trim(strings.TrimPrefix(path, "/api/pdf/"), "/")
+	parts := strings.Split(trimmed, "/")
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, nil, false
+	}
+	return id, parts[1:], true
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"pdf-management-system/internal/middleware"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/service"
+)
+
+type ProfileHandler struct {
+	Service *service.UserService
+}
+
+func NewProfileHandler(service *service.UserService) *ProfileHandler {
+	return &ProfileHandler{Service: service}
+}
+
+// Me serves GET/PATCH /api/me for the authenticated user.
+func (h *ProfileHandler) Me(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized", "")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		user, err := h.Service.GetUser(r.Context(), userID)
+		if err != nil {
+			respondUserError(w, err)
+			return
+		}
+		respondSuccess(w, "Profile retrieved successfully", user)
+	case http.MethodPatch:
+		middleware.AddAuditTarget(r, model.AuditTargetUser, userID)
+		var req model.UpdateProfileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body", "")
+			return
+		}
+		user, err := h.Service.UpdateProfile(r.Context(), userID, req)
+		if err != nil {
+			respondUserError(w, err)
+			return
+		}
+		respondSuccess(w, "Profile updated successfully", user)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"pdf-management-system/internal/middleware"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/service"
+	"strconv"
+	"strings"
+)
+
+type ShareHandler struct {
+	Service *service.ShareService
+}
+
+func NewShareHandler(service *service.ShareService) *ShareHandler {
+	return &ShareHandler{Service: service}
+}
+
+// Create serves POST /api/pdf/{id}/share
+func (h *ShareHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pdfID, rest, ok := parsePdfPath(r.URL.Path)
+	if !ok || len(rest) != 1 || rest[0] != "share" {
+		respondError(w, http.StatusBadRequest, "Invalid URL", "")
+		return
+	}
+	middleware.AddAuditTarget(r, model.AuditTargetPdf, pdfID)
+
+	var req model.CreateShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body", "")
+		return
+	}
+
+	userID, _ := middleware.UserIDFromContext(r.Context())
+	link, err := h.Service.Create(r.Context(), pdfID, userID, req)
+	if respondValidationError(w, err) {
+		return
+	}
+	if err != nil {
+		respondShareFileError(w, err)
+		return
+	}
+	middleware.SetAuditDetail(r, fmt.Sprintf("share_link_id=%d", link.ID))
+
+	respondSuccess(w, "Share link created, store it now: it will not be shown again", link)
+}
+
+// List serves GET /api/pdf/{id}/shares
+func (h *ShareHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pdfID, rest, ok := parsePdfPath(r.URL.Path)
+	if !ok || len(rest) != 1 || rest[0] != "shares" {
+		respondError(w, http.StatusBadRequest, "Invalid URL", "")
+		return
+	}
+
+	links, err := h.Service.List(r.Context(), pdfID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+		return
+	}
+	respondSuccess(w, "Share links retrieved successfully", links)
+}
+
+// Revoke serves DELETE /api/pdf/{id}/shares/{shareID}
+func (h *ShareHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pdfID, shareID, ok := parseSharePath(r.URL.Path, "")
+	if !ok {
+		respondError(w, http.StatusBadRequest, "Invalid URL", "")
+		return
+	}
+	middleware.AddAuditTarget(r, model.AuditTargetPdf, pdfID)
+	middleware.SetAuditDetail(r, fmt.Sprintf("share_link_id=%d", shareID))
+
+	link, err := h.Service.Revoke(r.Context(), pdfID, shareID)
+	if errors.Is(err, service.ErrShareLinkNotFound) {
+		respondError(w, http.StatusNotFound, err.Error(), "SHARE_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+		return
+	}
+	respondSuccess(w, "Share link revoked", link)
+}
+
+// Accesses serves GET /api/pdf/{id}/shares/{shareID}/accesses
+func (h *ShareHandler) Accesses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pdfID, shareID, ok := parseSharePath(r.URL.Path, "accesses")
+	if !ok {
+		respondError(w, http.StatusBadRequest, "Invalid URL", "")
+		return
+	}
+
+	accesses, err := h.Service.Accesses(r.Context(), pdfID, shareID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+		return
+	}
+	respondSuccess(w, "Share link accesses retrieved successfully", accesses)
+}
+
+// Download serves the public GET/POST /s/{token}. The password of a protected
+// link is sent in the X-Share-Password header or, from an HTML form, as the
+// "password" field of a POST.
+func (h *ShareHandler) Download(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(r.URL.Path, "/s/")
+	if token == r.URL.Path || token == "" || strings.Contains(token, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	password := r.Header.Get("X-Share-Password")
+	if password == "" && r.Method == http.MethodPost {
+		r.Body = http.MaxBytesReader(w, r.Body, 4<<10)
+		password = r.PostFormValue("password")
+	}
+
+	// Link tidak boleh di-cache atau diteruskan lewat Referer
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	link, pdf, path, err := h.Service.Open(r.Context(), token, password, clientInfo(r))
+	if link != nil {
+		middleware.AddAuditTarget(r, model.AuditTargetPdf, link.PdfFileID)
+		middleware.SetAuditDetail(r, fmt.Sprintf("share_link_id=%d", link.ID))
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrShareLinkNotFound):
+			respondError(w, http.StatusNotFound, err.Error(), "SHARE_NOT_FOUND")
+		case errors.Is(err, service.ErrShareLinkRevoked):
+			respondError(w, http.StatusGone, err.Error(), "SHARE_REVOKED")
+		case errors.Is(err, service.ErrShareLinkExpired):
+			respondError(w, http.StatusGone, err.Error(), "SHARE_EXPIRED")
+		case errors.Is(err, service.ErrShareLinkUsedUp):
+			respondError(w, http.StatusGone, err.Error(), "SHARE_LIMIT_REACHED")
+		case errors.Is(err, service.ErrSharePasswordRequired):
+			respondError(w, http.StatusUnauthorized, err.Error(), "PASSWORD_REQUIRED")
+		case errors.Is(err, service.ErrShareWrongPassword):
+			respondError(w, http.StatusUnauthorized, err.Error(), "WRONG_PASSWORD")
+		default:
+			respondShareFileError(w, err)
+		}
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to open file", "")
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to open file", "")
+		return
+	}
+
+	name := pdf.Filename
+	if pdf.OriginalName != nil && *pdf.OriginalName != "" {
+		name = *pdf.OriginalName
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	// Setiap request dihitung sebagai satu download, jadi selalu kirim file utuh
+	r.Header.Del("Range")
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
+
+// respondShareFileError maps the errors of a file that cannot be shared.
+func respondShareFileError(w http.ResponseWriter, err error) {
+	switch {
+	case err.Error() == "file not found":
+		respondError(w, http.StatusNotFound, "File not found", "")
+	case errors.Is(err, service.ErrFileDeleted):
+		respondError(w, http.StatusGone, err.Error(), "FILE_DELETED")
+	case errors.Is(err, service.ErrFileNotClean):
+		respondError(w, http.StatusForbidden, "File is pending malware scan or quarantined", "FILE_NOT_CLEAN")
+	default:
+		respondError(w, http.StatusInternalServerError, err.Error(), "")
+	}
+}
+
+// parseSharePath parses /api/pdf/{id}/shares/{shareID}[/suffix].
+func parseSharePath(path, suffix string) (int64, int64, bool) {
+	pdfID, rest, ok := parsePdfPath(path)
+	want := 2
+	if suffix != "" {
+		want = 3
+	}
+	if !ok || len(rest) != want || rest[0] != "shares" || (suffix != "" && rest[2] != suffix) {
+		return 0, 0, false
+	}
+	shareID, err := strconv.ParseInt(rest[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return pdfID, shareID, true
+}
@@ -0,0 +1,132 @@
+// Package health runs the dependency checks behind /readyz.
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"pdf-management-system/internal/fsutil"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check is one named dependency check. Run returns a short detail on success.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+type Result struct {
+	Status     string  `json:"status"`
+	Detail     string  `json:"detail,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// Checker runs all checks concurrently, each limited by Timeout.
+type Checker struct {
+	Checks  []Check
+	Timeout time.Duration
+}
+
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{Checks: checks, Timeout: timeout}
+}
+
+// Run returns whether every check passed and the result per check name.
+func (c *Checker) Run(ctx context.Context) (bool, map[string]Result) {
+	results := make(map[string]Result, len(c.Checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, check := range c.Checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+			defer cancel()
+
+			start := time.Now()
+			detail, err := check.Run(ctx)
+			res := Result{Status: StatusOK, Detail: detail, DurationMs: float64(time.Since(start).Microseconds()) / 1000}
+			if err != nil {
+				res.Status, res.Error = StatusFail, err.Error()
+			}
+
+			mu.Lock()
+			results[check.Name] = res
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		if r.Status != StatusOK {
+			return false, results
+		}
+	}
+	return true, results
+}
+
+// Database pings db.
+func Database(db *sql.DB) Check {
+	return Check{Name: "database", Run: func(ctx context.Context) (string, error) {
+		if err := db.PingContext(ctx); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d open connections", db.Stats().OpenConnections), nil
+	}}
+}
+
+// StorageWritable creates and removes a temporary file in dir.
+func StorageWritable(dir string) Check {
+	return Check{Name: "storage", Run: func(ctx context.Context) (string, error) {
+		f, err := os.CreateTemp(dir, ".readyz-*")
+		if err != nil {
+			return "", err
+		}
+		name := f.Name()
+		_, err = f.Write([]byte("ok"))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		os.Remove(name)
+		if err != nil {
+			return "", err
+		}
+		return dir + " is writable", nil
+	}}
+}
+
+// DiskSpace fails when the filesystem holding dir has less than minFreeBytes
+// or less than minFreePercent free. A zero threshold is not checked.
+func DiskSpace(dir string, minFreeBytes uint64, minFreePercent float64) Check {
+	return Check{Name: "disk_space", Run: func(ctx context.Context) (string, error) {
+		free, total, err := fsutil.DiskUsage(dir)
+		if err == fsutil.ErrUnsupported {
+			return "not supported on this platform", nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		percent := 0.0
+		if total > 0 {
+			percent = float64(free) / float64(total) * 100
+		}
+		detail := fmt.Sprintf("%d MB free (%.1f%%)", free>>20, percent)
+		if minFreeBytes > 0 && free < minFreeBytes {
+			return "", fmt.Errorf("%s, below minimum %d MB", detail, minFreeBytes>>20)
+		}
+		if minFreePercent > 0 && percent < minFreePercent {
+			return "", fmt.Errorf("%s, below minimum %.1f%%", detail, minFreePercent)
+		}
+		return detail, nil
+	}}
+}
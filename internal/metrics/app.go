@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"database/sql"
+	"runtime"
+)
+
+// Metrik aplikasi. Nama diawali pdfms_ supaya tidak bentrok dengan exporter lain.
+var (
+	HTTPRequests = NewCounterVec("pdfms_http_requests_total",
+		"HTTP requests by method, route pattern and status code.", "method", "route", "status")
+	HTTPDuration = NewHistogramVec("pdfms_http_request_duration_seconds",
+		"HTTP request latency by method and route pattern.", DefBuckets, "method", "route")
+
+	PdfGenerated = NewCounterVec("pdfms_pdf_generated_total",
+		"Report PDFs generated, by outcome (success, invalid, error).", "outcome")
+	PdfGenerateDuration = NewHistogramVec("pdfms_pdf_generate_duration_seconds",
+		"Time to generate a report PDF, including the logo fetch.", DefBuckets)
+	PdfUploads = NewCounterVec("pdfms_pdf_uploads_total",
+		"PDFs stored, by source (upload, chunked, import) and outcome.", "source", "outcome")
+	PdfUploadDuration = NewHistogramVec("pdfms_pdf_upload_duration_seconds",
+		"Time to store a PDF including the malware scan, by source.", DefBuckets, "source")
+	LogoFetchFailures = NewCounterVec("pdfms_logo_fetch_failures_total",
+		"Report logo downloads that failed; the PDF is generated without logo.")
+)
+
+func init() {
+	NewGaugeFunc("go_goroutines", "Number of goroutines.", func() []Sample {
+		return Value(float64(runtime.NumGoroutine()))
+	})
+	NewGaugeFunc("go_memstats_heap_alloc_bytes", "Bytes of allocated heap objects.", func() []Sample {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return Value(float64(m.HeapAlloc))
+	})
+}
+
+// RegisterDBStats exposes the connection pool statistics of db.
+func RegisterDBStats(db *sql.DB) {
+	stat := func(fn func(sql.DBStats) float64) func() []Sample {
+		return func() []Sample { return Value(fn(db.Stats())) }
+	}
+	NewGaugeFunc("pdfms_db_max_open_connections", "Maximum open connections to the database (0 = unlimited).",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	NewGaugeFunc("pdfms_db_open_connections", "Established connections, in use and idle.",
+		stat(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	NewGaugeFunc("pdfms_db_in_use_connections", "Connections currently in use.",
+		stat(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	NewGaugeFunc("pdfms_db_idle_connections", "Idle connections.",
+		stat(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	NewCounterFunc("pdfms_db_wait_count_total", "Connections waited for because the pool was exhausted.",
+		stat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	NewCounterFunc("pdfms_db_wait_duration_seconds_total", "Total time blocked waiting for a connection.",
+		stat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	NewCounterFunc("pdfms_db_max_idle_closed_total", "Connections closed due to SetMaxIdleConns.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	NewCounterFunc("pdfms_db_max_lifetime_closed_total", "Connections closed due to SetConnMaxLifetime.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
+}
+
+// Outcome maps an operation result to the outcome label.
+func Outcome(err error, invalid bool) string {
+	switch {
+	case err == nil:
+		return "success"
+	case invalid:
+		return "invalid"
+	}
+	return "error"
+}
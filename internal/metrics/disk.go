@@ -0,0 +1,21 @@
+package metrics
+
+import "pdf-management-system/internal/fsutil"
+
+// RegisterDiskUsage exposes free and total bytes of the filesystem holding dir.
+// Nothing is reported on platforms without disk usage support.
+func RegisterDiskUsage(dir string) {
+	usage := func(pick func(free, total uint64) uint64) func() []Sample {
+		return func() []Sample {
+			free, total, err := fsutil.DiskUsage(dir)
+			if err != nil {
+				return nil
+			}
+			return Value(float64(pick(free, total)))
+		}
+	}
+	NewGaugeFunc("pdfms_storage_free_bytes", "Free bytes available on the PDF storage filesystem.",
+		usage(func(free, _ uint64) uint64 { return free }))
+	NewGaugeFunc("pdfms_storage_size_bytes", "Total size of the PDF storage filesystem.",
+		usage(func(_, total uint64) uint64 { return total }))
+}
@@ -0,0 +1,272 @@
+// Package metrics is a small Prometheus text-format exporter: counters,
+// histograms and gauges computed at scrape time. Metrics register themselves
+// in Default when created.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets are latency buckets in seconds.
+var DefBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds metrics and writes them in the Prometheus text format.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// Default is the registry served on /metrics.
+var Default = &Registry{}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.collectors {
+		if existing.name() == c.name() {
+			panic("metrics: duplicate metric " + c.name())
+		}
+	}
+	r.collectors = append(r.collectors, c)
+}
+
+// Write writes every metric, sorted by name.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	cs := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+	sort.Slice(cs, func(i, j int) bool { return cs[i].name() < cs[j].name() })
+
+	bw := bufio.NewWriter(w)
+	for _, c := range cs {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// vec keeps one series per combination of label values.
+type vec[T any] struct {
+	metricName string
+	help       string
+	labels     []string
+
+	mu     sync.Mutex
+	series map[string]*T
+	values map[string][]string
+}
+
+func (v *vec[T]) name() string { return v.metricName }
+
+func (v *vec[T]) get(labelValues []string, init func() *T) *T {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.metricName, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if s, ok := v.series[key]; ok {
+		return s
+	}
+	s := init()
+	v.series[key] = s
+	v.values[key] = append([]string(nil), labelValues...)
+	return s
+}
+
+// sortedKeys returns the series keys in a stable order. Caller holds v.mu.
+func (v *vec[T]) sortedKeys() []string {
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func newVec[T any](name, help string, labels []string) vec[T] {
+	return vec[T]{metricName: name, help: help, labels: labels, series: map[string]*T{}, values: map[string][]string{}}
+}
+
+// CounterVec is a monotonically increasing counter with labels.
+type CounterVec struct {
+	vec[float64]
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newVec[float64](name, help, labels)}
+	if len(labels) == 0 {
+		c.Add(0) // tanpa label langsung tampil dengan nilai 0
+	}
+	Default.register(c)
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	p := c.get(labelValues, func() *float64 { return new(float64) })
+	c.mu.Lock()
+	*p += delta
+	c.mu.Unlock()
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	writeHeader(w, c.metricName, c.help, "counter")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, k := range c.sortedKeys() {
+		writeSample(w, c.metricName, c.labels, c.values[k], "", "", *c.series[k])
+	}
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// HistogramVec counts observations into cumulative buckets.
+type HistogramVec struct {
+	vec[histogram]
+	buckets []float64
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{vec: newVec[histogram](name, help, labels), buckets: buckets}
+	Default.register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	s := h.get(labelValues, func() *histogram { return &histogram{counts: make([]uint64, len(h.buckets))} })
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, b := range h.buckets {
+		if value <= b {
+			s.counts[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	writeHeader(w, h.metricName, h.help, "histogram")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, k := range h.sortedKeys() {
+		s, lv := h.series[k], h.values[k]
+		for i, b := range h.buckets {
+			writeSample(w, h.metricName+"_bucket", h.labels, lv, "le", formatFloat(b), float64(s.counts[i]))
+		}
+		writeSample(w, h.metricName+"_bucket", h.labels, lv, "le", "+Inf", float64(s.count))
+		writeSample(w, h.metricName+"_sum", h.labels, lv, "", "", s.sum)
+		writeSample(w, h.metricName+"_count", h.labels, lv, "", "", float64(s.count))
+	}
+}
+
+// Sample is one value of a GaugeFunc; LabelValues follow the gauge's labels.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// GaugeFunc reads its values when scraped, e.g. from the database.
+type GaugeFunc struct {
+	metricName string
+	help       string
+	kind       string
+	labels     []string
+	fn         func() []Sample
+}
+
+// NewGaugeFunc registers a gauge whose samples come from fn at scrape time.
+// fn returning nil skips the metric (e.g. the source is unavailable).
+func NewGaugeFunc(name, help string, fn func() []Sample, labels ...string) *GaugeFunc {
+	g := &GaugeFunc{metricName: name, help: help, kind: "gauge", labels: labels, fn: fn}
+	Default.register(g)
+	return g
+}
+
+// NewCounterFunc is like NewGaugeFunc for values that only go up (e.g. DB wait count).
+func NewCounterFunc(name, help string, fn func() []Sample, labels ...string) *GaugeFunc {
+	g := &GaugeFunc{metricName: name, help: help, kind: "counter", labels: labels, fn: fn}
+	Default.register(g)
+	return g
+}
+
+// Value is a shortcut for an unlabeled GaugeFunc sample.
+func Value(v float64) []Sample {
+	return []Sample{{Value: v}}
+}
+
+func (g *GaugeFunc) name() string { return g.metricName }
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	samples := g.fn()
+	if samples == nil {
+		return
+	}
+	writeHeader(w, g.metricName, g.help, g.kind)
+	for _, s := range samples {
+		writeSample(w, g.metricName, g.labels, s.LabelValues, "", "", s.Value)
+	}
+}
+
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help), name, kind)
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraLabel, extraValue string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, l, escapeLabel(values[i]))
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extraLabel, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
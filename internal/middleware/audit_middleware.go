@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"pdf-management-system/internal/model"
+)
+
+const auditKey contextKey = "audit"
+
+// AuditRecorder stores audit events (see service.AuditService).
+type AuditRecorder interface {
+	Record(ctx context.Context, e model.AuditEvent)
+}
+
+type auditTarget struct {
+	Type string
+	ID   int64
+}
+
+// auditEntry is filled in while the request runs: AuthMiddleware sets the
+// actor, handlers add targets and details.
+type auditEntry struct {
+	actorID *int64
+	targets []auditTarget
+	detail  string
+}
+
+// statusRecorder remembers the status code and body size written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += int64(n)
+	return n, err
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Audit records one event per target (or one without target) after next has
+// responded. The outcome follows the response status. Put it outside
+// AuthMiddleware so rejected requests are recorded too.
+func Audit(recorder AuditRecorder, action string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			entry := &auditEntry{}
+			rec := &statusRecorder{ResponseWriter: w}
+			next(rec, r.WithContext(context.WithValue(r.Context(), auditKey, entry)))
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			outcome := model.AuditSuccess
+			if status >= 400 {
+				outcome = model.AuditFailure
+			}
+
+			ip, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				ip = r.RemoteAddr
+			}
+			event := model.AuditEvent{
+				ActorID:    entry.actorID,
+				Action:     action,
+				IPAddress:  ip,
+				UserAgent:  r.UserAgent(),
+				Outcome:    outcome,
+				StatusCode: status,
+				Detail:     entry.detail,
+			}
+			// Tetap dicatat walaupun client sudah memutus koneksi
+			ctx := context.WithoutCancel(r.Context())
+			if len(entry.targets) == 0 {
+				recorder.Record(ctx, event)
+				return
+			}
+			for _, t := range entry.targets {
+				id := t.ID
+				event.TargetType, event.TargetID = t.Type, &id
+				recorder.Record(ctx, event)
+			}
+		}
+	}
+}
+
+func auditFromContext(ctx context.Context) *auditEntry {
+	entry, _ := ctx.Value(auditKey).(*auditEntry)
+	return entry
+}
+
+// SetAuditActor sets the user responsible for the action, for handlers that
+// run without AuthMiddleware (login, register). No-op outside Audit.
+func SetAuditActor(r *http.Request, userID int64) {
+	if entry := auditFromContext(r.Context()); entry != nil {
+		entry.actorID = &userID
+	}
+}
+
+// AddAuditTarget adds a pdf_files/users row affected by the request.
+func AddAuditTarget(r *http.Request, targetType string, id int64) {
+	if entry := auditFromContext(r.Context()); entry != nil {
+		entry.targets = append(entry.targets, auditTarget{Type: targetType, ID: id})
+	}
+}
+
+// SetAuditDetail sets free text stored with the event (e.g. the email of a failed login).
+func SetAuditDetail(r *http.Request, detail string) {
+	if entry := auditFromContext(r.Context()); entry != nil {
+		entry.detail = detail
+	}
+}
@@ -2,61 +2,158 @@ package middleware
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	"net/http"
-	"os"
+	"pdf-management-system/internal/logging"
+	"pdf-management-system/internal/model"
+	"slices"
 	"strings"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type contextKey string
 
 const UserIDKey contextKey = "userID"
 const RoleIDKey contextKey = "roleID"
+const ClaimsKey contextKey = "claims"
 
-func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Missing Authorization Header", http.StatusUnauthorized)
-			return
+// TokenValidator verifies an access token (signature, expiry, revocation).
+type TokenValidator interface {
+	ValidateAccessToken(ctx context.Context, tokenString string) (*model.TokenClaims, error)
+}
+
+// APIKeyValidator resolves an X-API-Key header to the claims of the key owner.
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key string) (*model.TokenClaims, error)
+}
+
+// AuthMiddleware returns a wrapper that requires a valid Bearer access token
+// or, when keys is not nil, an X-API-Key header.
+func AuthMiddleware(validator TokenValidator, keys APIKeyValidator) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			apiKey := r.Header.Get("X-API-Key")
+
+			var claims *model.TokenClaims
+			var err error
+			switch {
+			case authHeader == "" && apiKey != "" && keys != nil:
+				claims, err = keys.ValidateAPIKey(r.Context(), apiKey)
+				if err != nil {
+					http.Error(w, "Invalid or Expired API Key", http.StatusUnauthorized)
+					return
+				}
+			case authHeader == "":
+				http.Error(w, "Missing Authorization Header", http.StatusUnauthorized)
+				return
+			default:
+				parts := strings.Split(authHeader, " ")
+				if len(parts) != 2 || parts[0] != "Bearer" {
+					http.Error(w, "Invalid Authorization Header Format", http.StatusUnauthorized)
+					return
+				}
+
+				claims, err = validator.ValidateAccessToken(r.Context(), parts[1])
+				if err != nil {
+					http.Error(w, "Invalid or Expired Token", http.StatusUnauthorized)
+					return
+				}
+			}
+
+			SetAuditActor(r, claims.UserID)
+			logging.SetUserID(r.Context(), claims.UserID)
+
+			// Add claims to context
+			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+			ctx = context.WithValue(ctx, RoleIDKey, claims.RoleID)
+			ctx = context.WithValue(ctx, ClaimsKey, claims)
+
+			next(w, r.WithContext(ctx))
 		}
+	}
+}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Invalid Authorization Header Format", http.StatusUnauthorized)
+// RequireVerifiedEmail rejects users whose email is not verified. Must run after AuthMiddleware.
+func RequireVerifiedEmail(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok || !claims.EmailVerified {
+			respondForbidden(w, "Email address has not been verified", "EMAIL_NOT_VERIFIED")
 			return
 		}
+		next(w, r)
+	}
+}
 
-		tokenString := parts[1]
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "default_secret"
+// RequireRole rejects users whose role is not one of roleIDs. Must run after AuthMiddleware.
+func RequireRole(roleIDs ...int64) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			roleID, _ := RoleIDFromContext(r.Context())
+			for _, id := range roleIDs {
+				if id == roleID {
+					next(w, r)
+					return
+				}
+			}
+			respondForbidden(w, "You do not have permission to access this resource", "FORBIDDEN")
 		}
+	}
+}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// RequireScope limits API keys to routes covered by their scopes. Keys without
+// scopes and Bearer tokens pass. Must run after AuthMiddleware.
+func RequireScope(scope string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if ok && claims.APIKeyID != 0 && len(claims.Scopes) > 0 && !slices.Contains(claims.Scopes, scope) {
+				respondForbidden(w, "API key is missing the "+scope+" scope", "INSUFFICIENT_SCOPE")
+				return
 			}
-			return []byte(secret), nil
-		})
-
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid or Expired Token", http.StatusUnauthorized)
-			return
+			next(w, r)
 		}
+	}
+}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			http.Error(w, "Invalid Token Claims", http.StatusUnauthorized)
+// RequireUserSession rejects API keys, for account management routes that
+// need a logged-in user. Must run after AuthMiddleware.
+func RequireUserSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := ClaimsFromContext(r.Context())
+		if !ok || claims.APIKeyID != 0 {
+			respondForbidden(w, "This endpoint requires a user session, API keys are not accepted", "API_KEY_NOT_ALLOWED")
 			return
 		}
+		next(w, r)
+	}
+}
 
-		// Add claims to context
-		ctx := context.WithValue(r.Context(), UserIDKey, claims["user_id"])
-		ctx = context.WithValue(ctx, RoleIDKey, claims["role_id"])
+func respondForbidden(w http.ResponseWriter, message, errorCode string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(model.ApiResponse{
+		Success:   false,
+		Message:   message,
+		ErrorCode: errorCode,
+		RequestID: w.Header().Get(RequestIDHeader),
+	})
+}
 
-		next(w, r.WithContext(ctx))
-	}
+// UserIDFromContext returns the authenticated user's ID set by AuthMiddleware.
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(UserIDKey).(int64)
+	return id, ok
+}
+
+// RoleIDFromContext returns the authenticated user's role ID set by AuthMiddleware.
+func RoleIDFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(RoleIDKey).(int64)
+	return id, ok
+}
+
+// ClaimsFromContext returns the validated access token claims set by AuthMiddleware.
+func ClaimsFromContext(ctx context.Context) (*model.TokenClaims, bool) {
+	claims, ok := ctx.Value(ClaimsKey).(*model.TokenClaims)
+	return claims, ok
 }
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net"
+	"net/http"
+	"pdf-management-system/internal/logging"
+	"strings"
+	"time"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID gives every request an ID: a sane incoming X-Request-ID (from a
+// proxy) is kept, otherwise a new one is generated. The ID is put in the
+// context for logging and echoed in the X-Request-ID response header.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AccessLog writes one log line per request with status, size, latency and
+// the authenticated user. Must run inside RequestID.
+func AccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if status < 400 && (r.URL.Path == "/healthz" || r.URL.Path == "/readyz") {
+			// Probe dipanggil tiap beberapa detik, cukup di level debug
+			level = slog.LevelDebug
+		} else if status >= 500 {
+			level = slog.LevelError
+		} else if status >= 400 {
+			level = slog.LevelWarn
+		}
+
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		// user_id ditambahkan oleh logging handler dari context
+		slog.Log(r.Context(), level, "http request",
+			slog.String("method", r.Method),
+			slog.String("path", logPath(r.URL.Path)),
+			slog.Int("status", status),
+			slog.Int64("bytes", rec.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("ip", ip),
+			slog.String("user_agent", r.UserAgent()),
+		)
+	})
+}
+
+// logPath hides the token of public share links (/s/{token}); anyone with
+// log access could otherwise download the shared file.
+func logPath(path string) string {
+	if strings.HasPrefix(path, "/s/") && len(path) > len("/s/") {
+		return "/s/[redacted]"
+	}
+	return path
+}
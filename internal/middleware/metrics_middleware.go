@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"pdf-management-system/internal/metrics"
+	"strconv"
+	"time"
+)
+
+// Metrics counts requests and their latency per route. The route label is the
+// ServeMux pattern (e.g. "/api/pdf/"), not the raw path, so IDs in URLs do not
+// create a series per request.
+func Metrics(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		mux.ServeHTTP(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		method := methodLabel(r.Method)
+		metrics.HTTPRequests.Inc(method, route, strconv.Itoa(status))
+		metrics.HTTPDuration.Observe(time.Since(start).Seconds(), method, route)
+	})
+}
+
+// methodLabel maps methods outside the standard set to "OTHER", since any
+// token is a valid method and each one would otherwise be a new series.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
@@ -1,4 +1,5 @@
--- Database Schema for PDF Management System
+-- Baseline schema. Uses IF NOT EXISTS so databases created by the old
+-- initDB bootstrap can adopt the migration history without changes.
 
 CREATE TABLE IF NOT EXISTS pdf_files (
     id BIGSERIAL PRIMARY KEY,
@@ -17,6 +18,10 @@ CREATE TABLE IF NOT EXISTS roles (
     role VARCHAR(255) NOT NULL
 );
 
+INSERT INTO roles (role)
+SELECT r FROM (VALUES ('Project Manager'), ('Financial'), ('HRD')) AS seed(r)
+WHERE NOT EXISTS (SELECT 1 FROM roles);
+
 CREATE TABLE IF NOT EXISTS users (
     id BIGSERIAL PRIMARY KEY,
     address VARCHAR(255),
@@ -33,9 +38,5 @@ CREATE TABLE IF NOT EXISTS users (
     role_id BIGINT REFERENCES roles(id)
 );
 
--- Seed Initial Roles
-INSERT INTO roles (role) VALUES ('Project Manager'), ('Financial'), ('HRD') ON CONFLICT DO NOTHING;
-
--- Indexes (Optional for performance)
-CREATE INDEX idx_status ON pdf_files(status);
-CREATE INDEX idx_created_at ON pdf_files(created_at);
+CREATE INDEX IF NOT EXISTS idx_status ON pdf_files(status);
+CREATE INDEX IF NOT EXISTS idx_created_at ON pdf_files(created_at);
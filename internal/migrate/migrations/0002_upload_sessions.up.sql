@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS upload_sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id),
+    original_name VARCHAR(255) NOT NULL,
+    total_size BIGINT NOT NULL,
+    received_size BIGINT NOT NULL DEFAULT 0,
+    checksum VARCHAR(64),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('ACTIVE', 'COMPLETED', 'ABORTED', 'EXPIRED')),
+    pdf_file_id BIGINT REFERENCES pdf_files(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_expires_at ON upload_sessions(status, expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS fetched_at;
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS source_url;
@@ -0,0 +1,2 @@
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS source_url VARCHAR(2048);
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS fetched_at TIMESTAMP;
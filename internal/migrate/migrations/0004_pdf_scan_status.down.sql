@@ -0,0 +1,11 @@
+-- Files still waiting for (or failing) a scan cannot be represented in the old
+-- status set; treat them as deleted so they stay hidden from downloads.
+UPDATE pdf_files SET status = 'DELETED', deleted_at = COALESCE(deleted_at, CURRENT_TIMESTAMP)
+WHERE status IN ('PENDING_SCAN', 'QUARANTINED');
+
+ALTER TABLE pdf_files DROP CONSTRAINT IF EXISTS pdf_files_status_check;
+ALTER TABLE pdf_files ADD CONSTRAINT pdf_files_status_check
+    CHECK (status IN ('CREATED', 'UPLOADED', 'DELETED'));
+
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS scanned_at;
+ALTER TABLE pdf_files DROP COLUMN IF EXISTS scan_result;
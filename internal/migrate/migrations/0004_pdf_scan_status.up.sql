@@ -0,0 +1,6 @@
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS scan_result VARCHAR(255);
+ALTER TABLE pdf_files ADD COLUMN IF NOT EXISTS scanned_at TIMESTAMP;
+
+ALTER TABLE pdf_files DROP CONSTRAINT IF EXISTS pdf_files_status_check;
+ALTER TABLE pdf_files ADD CONSTRAINT pdf_files_status_check
+    CHECK (status IN ('CREATED', 'UPLOADED', 'DELETED', 'PENDING_SCAN', 'QUARANTINED'));
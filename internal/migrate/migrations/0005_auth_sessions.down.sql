@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS sessions_revoked_at;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
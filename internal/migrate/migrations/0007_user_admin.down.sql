@@ -0,0 +1,4 @@
+ALTER TABLE users DROP COLUMN IF EXISTS is_active;
+
+UPDATE users SET role_id = NULL WHERE role_id IN (SELECT id FROM roles WHERE role IN ('Admin', 'Staff'));
+DELETE FROM roles WHERE role IN ('Admin', 'Staff');
@@ -0,0 +1,6 @@
+-- Admin: kelola user & role. Staff: role default untuk registrasi mandiri.
+INSERT INTO roles (role)
+SELECT r FROM (VALUES ('Admin'), ('Staff')) AS seed(r)
+WHERE NOT EXISTS (SELECT 1 FROM roles WHERE roles.role = seed.r);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,21 @@
+-- Audit log percobaan login yang gagal / terkunci / dibuka admin
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ip_address VARCHAR(64),
+    user_agent VARCHAR(255),
+    reason VARCHAR(30) NOT NULL,
+    attempted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_email ON login_attempts(email, attempted_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts(ip_address, attempted_at);
+
+-- Penghitung gagal login per akun / IP, dipakai jika LOGIN_ATTEMPT_STORE=postgres
+CREATE TABLE IF NOT EXISTS login_throttle (
+    key VARCHAR(320) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP
+);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_users_oidc;
+
+-- Gagal jika ada email > 30 karakter, perbaiki datanya dulu
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(30);
+
+UPDATE users SET password = '' WHERE password IS NULL;
+ALTER TABLE users ALTER COLUMN password SET NOT NULL;
+ALTER TABLE users DROP COLUMN IF EXISTS oidc_subject;
+ALTER TABLE users DROP COLUMN IF EXISTS oidc_issuer;
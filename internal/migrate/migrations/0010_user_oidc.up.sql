@@ -0,0 +1,9 @@
+-- User SSO (OIDC) tidak punya password lokal
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oidc_issuer VARCHAR(255);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oidc_subject VARCHAR(255);
+ALTER TABLE users ALTER COLUMN password DROP NOT NULL;
+
+-- Email dari identity provider bisa lebih dari 30 karakter
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_oidc ON users(oidc_issuer, oidc_subject) WHERE oidc_subject IS NOT NULL;
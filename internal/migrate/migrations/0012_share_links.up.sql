@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS share_links (
+    id BIGSERIAL PRIMARY KEY,
+    pdf_file_id BIGINT NOT NULL REFERENCES pdf_files(id) ON DELETE CASCADE,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    password_hash VARCHAR(60),
+    max_downloads INT,
+    download_count INT NOT NULL DEFAULT 0,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    last_accessed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_links_pdf_file_id ON share_links(pdf_file_id);
+
+CREATE TABLE IF NOT EXISTS share_link_accesses (
+    id BIGSERIAL PRIMARY KEY,
+    share_link_id BIGINT NOT NULL REFERENCES share_links(id) ON DELETE CASCADE,
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(255),
+    outcome VARCHAR(20) NOT NULL,
+    accessed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_link_accesses_share_link_id ON share_link_accesses(share_link_id);
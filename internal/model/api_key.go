@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Scope API key. Key tanpa scope boleh mengakses semua endpoint PDF.
+const (
+	ScopePdfRead     = "pdf:read"     // list, thumbnail, preview
+	ScopePdfWrite    = "pdf:write"    // upload, import, delete
+	ScopePdfGenerate = "pdf:generate" // generate report
+	ScopePdfShare    = "pdf:share"    // share link
+)
+
+var APIKeyScopes = []string{ScopePdfRead, ScopePdfWrite, ScopePdfGenerate, ScopePdfShare}
+
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreatedAPIKey is returned once on creation; the plain key is not stored.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
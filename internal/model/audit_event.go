@@ -0,0 +1,77 @@
+package model
+
+import "time"
+
+// Hasil aksi yang dicatat di audit_events
+const (
+	AuditSuccess = "SUCCESS"
+	AuditFailure = "FAILURE"
+)
+
+// Jenis target audit, sesuai nama tabel
+const (
+	AuditTargetPdf  = "pdf_files"
+	AuditTargetUser = "users"
+)
+
+// Aksi yang dicatat
+const (
+	AuditPdfGenerate = "pdf.generate"
+	AuditPdfUpload   = "pdf.upload"
+	AuditPdfList     = "pdf.list"
+	AuditPdfDelete   = "pdf.delete"
+	AuditPdfImport   = "pdf.import"
+	AuditPdfDownload = "pdf.download"
+
+	AuditPdfShare         = "pdf.share"
+	AuditPdfShareRevoke   = "pdf.share_revoke"
+	AuditPdfShareDownload = "pdf.share_download"
+
+	AuditAuthRegister           = "auth.register"
+	AuditAuthLogin              = "auth.login"
+	AuditAuthOIDCLogin          = "auth.oidc_login"
+	AuditAuthRefresh            = "auth.refresh"
+	AuditAuthLogout             = "auth.logout"
+	AuditAuthLogoutAll          = "auth.logout_all"
+	AuditAuthVerifyEmail        = "auth.verify_email"
+	AuditAuthResendVerification = "auth.resend_verification"
+	AuditAuthForgotPassword     = "auth.forgot_password"
+	AuditAuthResetPassword      = "auth.reset_password"
+	AuditAuthChangePassword     = "auth.change_password"
+
+	AuditProfileUpdate = "profile.update"
+	AuditAPIKeyCreate  = "api_key.create"
+	AuditAPIKeyRevoke  = "api_key.revoke"
+
+	AuditAdminUserCreate     = "admin.user_create"
+	AuditAdminUserUpdate     = "admin.user_update"
+	AuditAdminUserDeactivate = "admin.user_deactivate"
+	AuditAdminUserUnlock     = "admin.user_unlock"
+)
+
+type AuditEvent struct {
+	ID         int64     `json:"id"`
+	ActorID    *int64    `json:"actor_id"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type,omitempty"`
+	TargetID   *int64    `json:"target_id"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	Outcome    string    `json:"outcome"`
+	StatusCode int       `json:"status_code"`
+	Detail     string    `json:"detail,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AuditFilter selects events for GET /api/admin/audit; zero values are ignored.
+type AuditFilter struct {
+	ActorID    *int64
+	Action     string
+	TargetType string
+	TargetID   *int64
+	Outcome    string
+	From       *time.Time
+	To         *time.Time
+	Page       int
+	Limit      int
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Alasan yang dicatat di tabel login_attempts
+const (
+	LoginInvalidCredentials = "INVALID_CREDENTIALS"
+	LoginLocked             = "LOCKED"
+	LoginUnlocked           = "UNLOCKED"
+)
+
+type LoginAttempt struct {
+	ID          int64     `json:"id"`
+	Email       string    `json:"email"`
+	UserID      *int64    `json:"user_id,omitempty"`
+	IPAddress   string    `json:"ip_address"`
+	UserAgent   string    `json:"user_agent"`
+	Reason      string    `json:"reason"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// LoginThrottle is the failure counter for one account ("account:<email>") or IP ("ip:<addr>").
+type LoginThrottle struct {
+	Key           string     `json:"key"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
+
+// UnlockRequest optionally also clears the counter of an IP address.
+type UnlockRequest struct {
+	IPAddress string `json:"ip_address"`
+}
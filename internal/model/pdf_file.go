@@ -8,6 +8,10 @@ const (
 	StatusCreated  PdfStatus = "CREATED"
 	StatusUploaded PdfStatus = "UPLOADED"
 	StatusDeleted  PdfStatus = "DELETED"
+
+	// Upload yang belum/tidak lolos scan malware, tidak bisa didownload
+	StatusPendingScan PdfStatus = "PENDING_SCAN"
+	StatusQuarantined PdfStatus = "QUARANTINED"
 )
 
 type PdfFile struct {
@@ -20,6 +24,10 @@ type PdfFile struct {
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
 	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	SourceURL    *string    `json:"source_url,omitempty"`
+	FetchedAt    *time.Time `json:"fetched_at,omitempty"`
+	ScanResult   *string    `json:"scan_result,omitempty"`
+	ScannedAt    *time.Time `json:"scanned_at,omitempty"`
 }
 
 type GeneratePdfRequest struct {
@@ -31,11 +39,18 @@ type GeneratePdfRequest struct {
 	Content         string `json:"content"`
 }
 
+type ImportPdfRequest struct {
+	URL string `json:"url"`
+}
+
 type ApiResponse struct {
-	Success   bool        `json:"success"`
-	Message   string      `json:"message"`
-	Data      interface{} `json:"data,omitempty"`
-	ErrorCode string      `json:"error_code,omitempty"`
+	Success   bool         `json:"success"`
+	Message   string       `json:"message"`
+	Data      interface{}  `json:"data,omitempty"`
+	ErrorCode string       `json:"error_code,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	// RequestID is set on errors so clients can report it
+	RequestID string `json:"request_id,omitempty"`
 }
 
 type Pagination struct {
@@ -49,3 +64,42 @@ type PaginatedResponse struct {
 	Data       interface{} `json:"data"`
 	Pagination Pagination  `json:"pagination"`
 }
+
+// PdfStatusStats is the file count and stored size of one status (for metrics).
+type PdfStatusStats struct {
+	Status PdfStatus
+	Files  int64
+	Bytes  int64
+}
+
+// UploadResult is the per-file outcome of a multi-file upload.
+type UploadResult struct {
+	Filename  string   `json:"filename"`
+	Success   bool     `json:"success"`
+	Data      *PdfFile `json:"data,omitempty"`
+	ErrorCode string   `json:"error_code,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// ReconcileReport lists mismatches between stored files and pdf_files rows.
+type ReconcileReport struct {
+	// Files on disk without a row
+	OrphanFiles []string
+	// Temporary files left by interrupted writes
+	StaleTempFiles []string
+	// Non-deleted rows whose file is gone
+	MissingFiles []PdfFile
+	Fixed        bool
+}
+
+// Clean reports whether no mismatch was found.
+func (r *ReconcileReport) Clean() bool {
+	return len(r.OrphanFiles) == 0 && len(r.StaleTempFiles) == 0 && len(r.MissingFiles) == 0
+}
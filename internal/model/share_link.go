@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// Hasil akses link share yang dicatat di share_link_accesses
+const (
+	ShareDownloaded      = "DOWNLOADED"
+	SharePasswordNeeded  = "PASSWORD_REQUIRED"
+	ShareWrongPassword   = "WRONG_PASSWORD"
+	ShareExpired         = "EXPIRED"
+	ShareRevoked         = "REVOKED"
+	ShareLimitReached    = "LIMIT_REACHED"
+	ShareFileUnavailable = "FILE_UNAVAILABLE"
+)
+
+// ShareLink gives access to one PDF without an account, until it expires,
+// is revoked or MaxDownloads is reached.
+type ShareLink struct {
+	ID             int64      `json:"id"`
+	PdfFileID      int64      `json:"pdf_file_id"`
+	CreatedBy      *int64     `json:"created_by"`
+	TokenHash      string     `json:"-"`
+	PasswordHash   *string    `json:"-"`
+	HasPassword    bool       `json:"has_password"`
+	MaxDownloads   *int       `json:"max_downloads"`
+	DownloadCount  int        `json:"download_count"`
+	FailedAttempts int        `json:"failed_attempts"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+}
+
+// CreatedShareLink is returned once on creation; the token is not stored.
+type CreatedShareLink struct {
+	ShareLink
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+type CreateShareLinkRequest struct {
+	ExpiresAt    *time.Time `json:"expires_at"`
+	Password     string     `json:"password"`
+	MaxDownloads *int       `json:"max_downloads"`
+}
+
+// ShareLinkAccess is one request to GET /s/{token}.
+type ShareLinkAccess struct {
+	ID          int64     `json:"id"`
+	ShareLinkID int64     `json:"share_link_id"`
+	IPAddress   string    `json:"ip_address"`
+	UserAgent   string    `json:"user_agent"`
+	Outcome     string    `json:"outcome"`
+	AccessedAt  time.Time `json:"accessed_at"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+type UploadSessionStatus string
+
+const (
+	UploadActive    UploadSessionStatus = "ACTIVE"
+	UploadCompleted UploadSessionStatus = "COMPLETED"
+	UploadAborted   UploadSessionStatus = "ABORTED"
+	UploadExpired   UploadSessionStatus = "EXPIRED"
+)
+
+type UploadSession struct {
+	ID           string              `json:"id"`
+	UserID       int64               `json:"user_id"`
+	OriginalName string              `json:"original_name"`
+	TotalSize    int64               `json:"total_size"`
+	Offset       int64               `json:"offset"`
+	Checksum     *string             `json:"checksum,omitempty"` // sha256 (hex) of the whole file, optional
+	Status       UploadSessionStatus `json:"status"`
+	PdfFileID    *int64              `json:"pdf_file_id,omitempty"`
+	ChunkSize    int64               `json:"chunk_size"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    *time.Time          `json:"updated_at,omitempty"`
+	ExpiresAt    time.Time           `json:"expires_at"`
+}
+
+type InitUploadRequest struct {
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"` // optional sha256 (hex) of the whole file
+}
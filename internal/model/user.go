@@ -16,7 +16,11 @@ type User struct {
 	PhoneNumber     string     `json:"phone_number"`
 	PostCode        string     `json:"post_code"`
 	RoleID          int64      `json:"role_id"`
+	RoleName        string     `json:"role_name,omitempty"`
 	IsEmailVerified bool       `json:"is_email_verified"` // tinyint usually maps to bool or int8
+	IsActive        bool       `json:"is_active"`
+	OIDCIssuer      *string    `json:"oidc_issuer,omitempty"` // diisi untuk user SSO
+	OIDCSubject     *string    `json:"oidc_subject,omitempty"`
 	CreatedBy       *int64     `json:"created_by"`
 	CreatedDate     time.Time  `json:"created_date"`
 	ModifiedBy      *int64     `json:"modified_by"`
@@ -30,7 +34,36 @@ type RegisterRequest struct {
 	Address     string `json:"address"`
 	PhoneNumber string `json:"phone_number"`
 	PostCode    string `json:"post_code"`
-	RoleID      int64  `json:"role_id"` // User sends role preference? Or default. Let's allow sending.
+	// Role tidak bisa dipilih sendiri, registrasi selalu mendapat role default
+}
+
+// CreateUserRequest is used by admins to create an account with a given role.
+type CreateUserRequest struct {
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	Address     string `json:"address"`
+	PhoneNumber string `json:"phone_number"`
+	PostCode    string `json:"post_code"`
+	RoleID      int64  `json:"role_id"`
+}
+
+// UpdateProfileRequest is what a user may change on their own account; nil fields are left unchanged.
+type UpdateProfileRequest struct {
+	Name        *string `json:"name"`
+	Address     *string `json:"address"`
+	PhoneNumber *string `json:"phone_number"`
+	PostCode    *string `json:"post_code"`
+}
+
+// UpdateUserRequest is a partial update by an admin; nil fields are left unchanged.
+type UpdateUserRequest struct {
+	Name        *string `json:"name"`
+	Address     *string `json:"address"`
+	PhoneNumber *string `json:"phone_number"`
+	PostCode    *string `json:"post_code"`
+	RoleID      *int64  `json:"role_id"`
+	IsActive    *bool   `json:"is_active"`
 }
 
 type LoginRequest struct {
@@ -39,6 +72,54 @@ type LoginRequest struct {
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string `json:"token"` // access token
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // access token lifetime in seconds
+	User         User   `json:"user"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"` // optional, also revokes this session's refresh token
+}
+
+// TokenClaims are the validated claims of an access token.
+type TokenClaims struct {
+	UserID        int64
+	RoleID        int64
+	JTI           string
+	EmailVerified bool
+	IssuedAt      time.Time
+	ExpiresAt     time.Time
+
+	// Diisi jika request diautentikasi dengan X-API-Key
+	APIKeyID int64
+	Scopes   []string
+}
+
+// ClientInfo identifies where a session was created from.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
 }
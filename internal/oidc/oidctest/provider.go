@@ -0,0 +1,251 @@
+// Package oidctest is a tiny OpenID Connect provider for tests and local SSO
+// development (see cmd/mockidp). It approves every login without asking and
+// signs ID tokens with a key generated at startup. Never use it in production.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "mock-1"
+
+type authCode struct {
+	ClientID    string
+	RedirectURI string
+	Nonce       string
+	Challenge   string
+	Expires     time.Time
+}
+
+// Provider serves discovery, /authorize, /token and /jwks. Change its fields
+// through Configure once it is serving requests.
+type Provider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+
+	// User put in the ID token
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+
+	// Claims overrides claims of the issued ID tokens (e.g. "aud" or
+	// "nonce"); a nil value removes the claim.
+	Claims map[string]interface{}
+	// SigningMethod defaults to RS256 with the provider key. HS* methods sign
+	// with ClientSecret and "none" leaves the token unsigned, both of which
+	// a relying party must reject.
+	SigningMethod jwt.SigningMethod
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]authCode
+}
+
+// New returns a provider for one client with a fresh RSA key. Set Issuer to
+// the URL it is served on before the first request.
+func New(clientID, clientSecret string) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		Email:         "sso.user@example.com",
+		EmailVerified: true,
+		Name:          "SSO User",
+		key:           key,
+		codes:         map[string]authCode{},
+	}, nil
+}
+
+// Configure runs fn with the provider locked, e.g. to change the user between logins.
+func (p *Provider) Configure(fn func(p *Provider)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fn(p)
+}
+
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		p.discovery(w, r)
+	case "/authorize":
+		p.authorize(w, r)
+	case "/token":
+		p.token(w, r)
+	case "/jwks":
+		p.jwks(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	issuer := p.Issuer
+	p.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize langsung menyetujui login dan redirect balik dengan code
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	q := r.URL.Query()
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || q.Get("client_id") != p.ClientID || q.Get("response_type") != "code" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	p.codes[code] = authCode{
+		ClientID:    q.Get("client_id"),
+		RedirectURI: q.Get("redirect_uri"),
+		Nonce:       q.Get("nonce"),
+		Challenge:   q.Get("code_challenge"),
+		Expires:     time.Now().Add(time.Minute),
+	}
+
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	clientID, secret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID, secret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if clientID != p.ClientID || secret != p.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	c, found := p.codes[r.PostFormValue("code")]
+	delete(p.codes, r.PostFormValue("code"))
+
+	verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !found || time.Now().After(c.Expires) || c.RedirectURI != r.PostFormValue("redirect_uri") ||
+		(c.Challenge != "" && base64.RawURLEncoding.EncodeToString(verifier[:]) != c.Challenge) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	signed, err := p.idToken(c)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+func (p *Provider) idToken(c authCode) (string, error) {
+	now := time.Now()
+	sub := sha256.Sum256([]byte(p.Email))
+	claims := jwt.MapClaims{
+		"iss":            p.Issuer,
+		"sub":            hex.EncodeToString(sub[:8]),
+		"aud":            c.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          c.Nonce,
+		"email":          p.Email,
+		"email_verified": p.EmailVerified,
+		"name":           p.Name,
+		"groups":         p.Groups,
+	}
+	for name, v := range p.Claims {
+		if v == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = v
+		}
+	}
+
+	method := p.SigningMethod
+	if method == nil {
+		method = jwt.SigningMethodRS256
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = keyID
+
+	var key interface{} = p.key
+	if _, ok := method.(*jwt.SigningMethodHMAC); ok {
+		key = []byte(p.ClientSecret)
+	} else if method == jwt.SigningMethodNone {
+		key = jwt.UnsafeAllowNoneSignatureType
+	}
+	return token.SignedString(key)
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"pdf-management-system/internal/model"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type APIKeyRepository struct {
+	DB *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{DB: db}
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, created_at, expires_at, last_used_at, revoked_at`
+
+func scanAPIKey(row rowScanner) (*model.APIKey, error) {
+	var k model.APIKey
+	err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, pq.Array(&k.Scopes), &k.CreatedAt, &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	if k.Scopes == nil {
+		k.Scopes = []string{}
+	}
+	return &k, nil
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, k *model.APIKey) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	return r.DB.QueryRowContext(ctx, query, k.UserID, k.Name, k.Prefix, k.KeyHash, pq.Array(k.Scopes), k.CreatedAt, k.ExpiresAt).Scan(&k.ID)
+}
+
+func (r *APIKeyRepository) FindByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return scanAPIKey(r.DB.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash))
+}
+
+func (r *APIKeyRepository) FindByUser(ctx context.Context, userID int64) ([]model.APIKey, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke revokes an active key owned by userID. Returns sql.ErrNoRows if there is none.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id, userID int64) (*model.APIKey, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL RETURNING ` + apiKeyColumns
+	return scanAPIKey(r.DB.QueryRowContext(ctx, query, time.Now(), id, userID))
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, at, id)
+	return err
+}
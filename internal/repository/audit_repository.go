@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"pdf-management-system/internal/model"
+	"strings"
+)
+
+type AuditRepository struct {
+	DB *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{DB: db}
+}
+
+const auditColumns = `id, actor_id, action, COALESCE(target_type, ''), target_id, COALESCE(ip_address, ''), COALESCE(user_agent, ''), outcome, status_code, COALESCE(detail, ''), created_at`
+
+func (r *AuditRepository) Create(ctx context.Context, e *model.AuditEvent) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO audit_events (actor_id, action, target_type, target_id, ip_address, user_agent, outcome, status_code, detail, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, NULLIF($9, ''), $10)
+		RETURNING id
+	`
+	return r.DB.QueryRowContext(ctx, query, e.ActorID, e.Action, e.TargetType, e.TargetID, e.IPAddress, e.UserAgent, e.Outcome, e.StatusCode, e.Detail, e.CreatedAt).Scan(&e.ID)
+}
+
+// FindAll returns one page of events matching f, newest first.
+func (r *AuditRepository) FindAll(ctx context.Context, f model.AuditFilter) ([]model.AuditEvent, int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	where, args := auditWhere(f)
+
+	var total int64
+	if err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_events`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_events` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, f.Limit, (f.Page-1)*f.Limit)
+
+	events := []model.AuditEvent{}
+	err := r.each(ctx, query, args, func(e *model.AuditEvent) error {
+		events = append(events, *e)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// Each calls fn for every event matching f (page and limit ignored), oldest
+// first, without loading the whole result into memory. Used for CSV export.
+func (r *AuditRepository) Each(ctx context.Context, f model.AuditFilter, fn func(*model.AuditEvent) error) error {
+	where, args := auditWhere(f)
+	return r.each(ctx, `SELECT `+auditColumns+` FROM audit_events`+where+` ORDER BY created_at ASC, id ASC`, args, fn)
+}
+
+func (r *AuditRepository) each(ctx context.Context, query string, args []interface{}, fn func(*model.AuditEvent) error) error {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e model.AuditEvent
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.TargetType, &e.TargetID, &e.IPAddress, &e.UserAgent, &e.Outcome, &e.StatusCode, &e.Detail, &e.CreatedAt); err != nil {
+			return err
+		}
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func auditWhere(f model.AuditFilter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.ActorID != nil {
+		add("actor_id = $%d", *f.ActorID)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.TargetType != "" {
+		add("target_type = $%d", f.TargetType)
+	}
+	if f.TargetID != nil {
+		add("target_id = $%d", *f.TargetID)
+	}
+	if f.Outcome != "" {
+		add("outcome = $%d", f.Outcome)
+	}
+	if f.From != nil {
+		add("created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("created_at < $%d", *f.To)
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"pdf-management-system/internal/model"
+	"time"
+)
+
+// LoginAttemptRepository stores the login audit log and, for multi-instance
+// deployments, the shared failed-login counters.
+type LoginAttemptRepository struct {
+	DB *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{DB: db}
+}
+
+func (r *LoginAttemptRepository) RecordAttempt(ctx context.Context, a *model.LoginAttempt) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO login_attempts (email, user_id, ip_address, user_agent, reason, attempted_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	return r.DB.QueryRowContext(ctx, query, a.Email, a.UserID, a.IPAddress, a.UserAgent, a.Reason, a.AttemptedAt).Scan(&a.ID)
+}
+
+func (r *LoginAttemptRepository) Get(ctx context.Context, key string) (*model.LoginThrottle, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	t := model.LoginThrottle{Key: key}
+	err := r.DB.QueryRowContext(ctx, `SELECT failures, last_failure_at, locked_until FROM login_throttle WHERE key = $1`, key).
+		Scan(&t.Failures, &t.LastFailureAt, &t.LockedUntil)
+	if err == sql.ErrNoRows {
+		return &t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RecordFailure atomically increments the counter, starting over from 1 when
+// the previous failure is older than window.
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO login_throttle (key, failures, last_failure_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_throttle.last_failure_at < $3 THEN 1 ELSE login_throttle.failures + 1 END,
+			last_failure_at = $2
+		RETURNING failures
+	`
+	var failures int
+	err := r.DB.QueryRowContext(ctx, query, key, now, now.Add(-window)).Scan(&failures)
+	return failures, err
+}
+
+func (r *LoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, `UPDATE login_throttle SET locked_until = $1 WHERE key = $2`, until, key)
+	return err
+}
+
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM login_throttle WHERE key = $1`, key)
+	return err
+}
+
+// Purge removes counters that are outside the window and no longer locked.
+func (r *LoginAttemptRepository) Purge(ctx context.Context, now time.Time, window time.Duration) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM login_throttle WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < $2)`, now.Add(-window), now)
+	return err
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"pdf-management-system/internal/model"
@@ -15,24 +16,94 @@ func NewPdfRepository(db *sql.DB) *PdfRepository {
 	return &PdfRepository{DB: db}
 }
 
-func (r *PdfRepository) Create(pdf *model.PdfFile) error {
+// CreateWithFile inserts pdf inside a transaction and calls publish (moving
+// the file into place) before committing. The insert is rolled back if
+// publish fails; if the commit itself fails the caller must remove the file.
+func (r *PdfRepository) CreateWithFile(ctx context.Context, pdf *model.PdfFile, publish func() error) error {
+	return r.createWithFile(ctx, pdf, publish, nil)
+}
+
+// CreateFromUpload is CreateWithFile for a resumable upload: the session is
+// marked COMPLETED in the same transaction. Returns sql.ErrNoRows (and
+// inserts nothing) if the session is no longer ACTIVE.
+func (r *PdfRepository) CreateFromUpload(ctx context.Context, pdf *model.PdfFile, sessionID string, publish func() error) error {
+	return r.createWithFile(ctx, pdf, publish, func(ctx context.Context, tx *sql.Tx) error {
+		query := `UPDATE upload_sessions SET status = 'COMPLETED', pdf_file_id = $1, updated_at = $2 WHERE id = $3 AND status = 'ACTIVE'`
+		res, err := tx.ExecContext(ctx, query, pdf.ID, time.Now(), sessionID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n != 1 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// createWithFile runs the insert, then also (optional, in the same
+// transaction), then publish, and commits.
+func (r *PdfRepository) createWithFile(ctx context.Context, pdf *model.PdfFile, publish func() error, also func(context.Context, *sql.Tx) error) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO pdf_files (filename, original_name, filepath, size, status, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO pdf_files (filename, original_name, filepath, size, status, created_at, source_url, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
 	`
-	return r.DB.QueryRow(query, pdf.Filename, pdf.OriginalName, pdf.Filepath, pdf.Size, pdf.Status, time.Now()).Scan(&pdf.ID)
+	if err := tx.QueryRowContext(ctx, query, pdf.Filename, pdf.OriginalName, pdf.Filepath, pdf.Size, pdf.Status, time.Now(), pdf.SourceURL, pdf.FetchedAt).Scan(&pdf.ID); err != nil {
+		return err
+	}
+	if also != nil {
+		if err := also(ctx, tx); err != nil {
+			return err
+		}
+	}
+	if err := publish(); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *PdfRepository) FindByID(id int64) (*model.PdfFile, error) {
+func (r *PdfRepository) FindByID(ctx context.Context, id int64) (*model.PdfFile, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		SELECT id, filename, original_name, filepath, size, status, created_at, updated_at, deleted_at
+		SELECT id, filename, original_name, filepath, size, status, created_at, updated_at, deleted_at, source_url, fetched_at, scan_result, scanned_at
 		FROM pdf_files
 		WHERE id = $1
 	`
 	var pdf model.PdfFile
-	err := r.DB.QueryRow(query, id).Scan(
-		&pdf.ID, &pdf.Filename, &pdf.OriginalName, &pdf.Filepath, &pdf.Size, &pdf.Status, &pdf.CreatedAt, &pdf.UpdatedAt, &pdf.DeletedAt,
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(
+		&pdf.ID, &pdf.Filename, &pdf.OriginalName, &pdf.Filepath, &pdf.Size, &pdf.Status, &pdf.CreatedAt, &pdf.UpdatedAt, &pdf.DeletedAt, &pdf.SourceURL, &pdf.FetchedAt, &pdf.ScanResult, &pdf.ScannedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &pdf, nil
+}
+
+func (r *PdfRepository) FindByFilename(ctx context.Context, filename string) (*model.PdfFile, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, filename, original_name, filepath, size, status, created_at, updated_at, deleted_at, source_url, fetched_at, scan_result, scanned_at
+		FROM pdf_files
+		WHERE filename = $1
+	`
+	var pdf model.PdfFile
+	err := r.DB.QueryRowContext(ctx, query, filename).Scan(
+		&pdf.ID, &pdf.Filename, &pdf.OriginalName, &pdf.Filepath, &pdf.Size, &pdf.Status, &pdf.CreatedAt, &pdf.UpdatedAt, &pdf.DeletedAt, &pdf.SourceURL, &pdf.FetchedAt, &pdf.ScanResult, &pdf.ScannedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -40,11 +111,14 @@ func (r *PdfRepository) FindByID(id int64) (*model.PdfFile, error) {
 	return &pdf, nil
 }
 
-func (r *PdfRepository) FindAll(status string, page, limit int) ([]model.PdfFile, int64, error) {
+func (r *PdfRepository) FindAll(ctx context.Context, status string, page, limit int) ([]model.PdfFile, int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	offset := (page - 1) * limit
 
 	// Base query
-	query := `SELECT id, filename, original_name, filepath, size, status, created_at, updated_at, deleted_at FROM pdf_files WHERE 1=1`
+	query := `SELECT id, filename, original_name, filepath, size, status, created_at, updated_at, deleted_at, source_url, fetched_at, scan_result, scanned_at FROM pdf_files WHERE 1=1`
 	countQuery := `SELECT COUNT(*) FROM pdf_files WHERE 1=1`
 	args := []interface{}{}
 	argId := 1
@@ -77,12 +151,12 @@ func (r *PdfRepository) FindAll(status string, page, limit int) ([]model.PdfFile
 	var total int64
 	// We need args for count query (only status)
 	countArgs := args[:argId-1]
-	err := r.DB.QueryRow(countQuery, countArgs...).Scan(&total)
+	err := r.DB.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	rows, err := r.DB.Query(query, args...)
+	rows, err := r.DB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -91,7 +165,7 @@ func (r *PdfRepository) FindAll(status string, page, limit int) ([]model.PdfFile
 	var files []model.PdfFile
 	for rows.Next() {
 		var pdf model.PdfFile
-		if err := rows.Scan(&pdf.ID, &pdf.Filename, &pdf.OriginalName, &pdf.Filepath, &pdf.Size, &pdf.Status, &pdf.CreatedAt, &pdf.UpdatedAt, &pdf.DeletedAt); err != nil {
+		if err := rows.Scan(&pdf.ID, &pdf.Filename, &pdf.OriginalName, &pdf.Filepath, &pdf.Size, &pdf.Status, &pdf.CreatedAt, &pdf.UpdatedAt, &pdf.DeletedAt, &pdf.SourceURL, &pdf.FetchedAt, &pdf.ScanResult, &pdf.ScannedAt); err != nil {
 			return nil, 0, err
 		}
 		files = append(files, pdf)
@@ -100,12 +174,15 @@ func (r *PdfRepository) FindAll(status string, page, limit int) ([]model.PdfFile
 	return files, total, nil
 }
 
-func (r *PdfRepository) SoftDelete(id int64) error {
+func (r *PdfRepository) SoftDelete(ctx context.Context, id int64) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	// Check if exists first? Or just update.
 	// Requirement: Return error if file not found OR already deleted.
 
 	// We can check first
-	pdf, err := r.FindByID(id)
+	pdf, err := r.FindByID(ctx, id)
 	if err == sql.ErrNoRows {
 		return fmt.Errorf("file not found")
 	} else if err != nil {
@@ -121,6 +198,102 @@ func (r *PdfRepository) SoftDelete(id int64) error {
 		SET status = 'DELETED', deleted_at = $1
 		WHERE id = $2
 	`
-	_, err = r.DB.Exec(query, time.Now(), id)
+	_, err = r.DB.ExecContext(ctx, query, time.Now(), id)
+	return err
+}
+
+// UpdateScanResult records a scanner verdict and moves the file to the resulting status.
+func (r *PdfRepository) UpdateScanResult(ctx context.Context, id int64, status model.PdfStatus, result string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE pdf_files
+		SET status = $1, scan_result = $2, scanned_at = $3, updated_at = $3
+		WHERE id = $4
+	`
+	_, err := r.DB.ExecContext(ctx, query, status, result, time.Now(), id)
+	return err
+}
+
+// FindPendingScan returns up to limit PENDING_SCAN rows with an id above
+// afterID, ordered by id, so callers can page through all of them.
+func (r *PdfRepository) FindPendingScan(ctx context.Context, afterID int64, limit int) ([]model.PdfFile, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, filename, original_name, filepath, size, status, created_at, updated_at, deleted_at, source_url, fetched_at, scan_result, scanned_at FROM pdf_files WHERE status = 'PENDING_SCAN' AND id > $1 ORDER BY id LIMIT $2`
+	rows, err := r.DB.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []model.PdfFile
+	for rows.Next() {
+		var pdf model.PdfFile
+		if err := rows.Scan(&pdf.ID, &pdf.Filename, &pdf.OriginalName, &pdf.Filepath, &pdf.Size, &pdf.Status, &pdf.CreatedAt, &pdf.UpdatedAt, &pdf.DeletedAt, &pdf.SourceURL, &pdf.FetchedAt, &pdf.ScanResult, &pdf.ScannedAt); err != nil {
+			return nil, err
+		}
+		files = append(files, pdf)
+	}
+	return files, rows.Err()
+}
+
+// StatsByStatus returns the number of files and their total size per status.
+func (r *PdfRepository) StatsByStatus(ctx context.Context) ([]model.PdfStatusStats, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, `SELECT status, COUNT(*), COALESCE(SUM(size), 0) FROM pdf_files GROUP BY status ORDER BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []model.PdfStatusStats
+	for rows.Next() {
+		var s model.PdfStatusStats
+		if err := rows.Scan(&s.Status, &s.Files, &s.Bytes); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// Each streams every row, including DELETED ones, ordered by id. No query
+// timeout is applied so large tables can be scanned (used by reconcile).
+func (r *PdfRepository) Each(ctx context.Context, fn func(*model.PdfFile) error) error {
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, filename, original_name, filepath, size, status, created_at, updated_at, deleted_at, source_url, fetched_at, scan_result, scanned_at FROM pdf_files ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pdf model.PdfFile
+		if err := rows.Scan(&pdf.ID, &pdf.Filename, &pdf.OriginalName, &pdf.Filepath, &pdf.Size, &pdf.Status, &pdf.CreatedAt, &pdf.UpdatedAt, &pdf.DeletedAt, &pdf.SourceURL, &pdf.FetchedAt, &pdf.ScanResult, &pdf.ScannedAt); err != nil {
+			return err
+		}
+		if err := fn(&pdf); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// MarkMissing marks a row whose file no longer exists on disk as DELETED.
+func (r *PdfRepository) MarkMissing(ctx context.Context, id int64) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	query := `
+		UPDATE pdf_files
+		SET status = 'DELETED', deleted_at = $1, updated_at = $1
+		WHERE id = $2 AND status <> 'DELETED'
+	`
+	_, err := r.DB.ExecContext(ctx, query, now, id)
 	return err
 }
@@ -0,0 +1,16 @@
+// Package repository holds the PostgreSQL data access. Every method takes the
+// caller's context so queries stop when the request is cancelled.
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// queryTimeout bounds a single repository call; a shorter deadline on the
+// caller's context still wins.
+const queryTimeout = 5 * time.Second
+
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, queryTimeout)
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"pdf-management-system/internal/model"
+	"time"
+)
+
+type ShareLinkRepository struct {
+	DB *sql.DB
+}
+
+func NewShareLinkRepository(db *sql.DB) *ShareLinkRepository {
+	return &ShareLinkRepository{DB: db}
+}
+
+const shareLinkColumns = `id, pdf_file_id, created_by, token_hash, password_hash, max_downloads, download_count, failed_attempts, created_at, expires_at, revoked_at, last_accessed_at`
+
+func scanShareLink(row rowScanner) (*model.ShareLink, error) {
+	var l model.ShareLink
+	err := row.Scan(&l.ID, &l.PdfFileID, &l.CreatedBy, &l.TokenHash, &l.PasswordHash, &l.MaxDownloads, &l.DownloadCount, &l.FailedAttempts, &l.CreatedAt, &l.ExpiresAt, &l.RevokedAt, &l.LastAccessedAt)
+	if err != nil {
+		return nil, err
+	}
+	l.HasPassword = l.PasswordHash != nil
+	return &l, nil
+}
+
+func (r *ShareLinkRepository) Create(ctx context.Context, l *model.ShareLink) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO share_links (pdf_file_id, created_by, token_hash, password_hash, max_downloads, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	return r.DB.QueryRowContext(ctx, query, l.PdfFileID, l.CreatedBy, l.TokenHash, l.PasswordHash, l.MaxDownloads, l.CreatedAt, l.ExpiresAt).Scan(&l.ID)
+}
+
+func (r *ShareLinkRepository) FindByHash(ctx context.Context, hash string) (*model.ShareLink, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return scanShareLink(r.DB.QueryRowContext(ctx, `SELECT `+shareLinkColumns+` FROM share_links WHERE token_hash = $1`, hash))
+}
+
+// FindActiveByPdf returns the links of a file that are not revoked, expired or used up.
+func (r *ShareLinkRepository) FindActiveByPdf(ctx context.Context, pdfID int64, now time.Time) ([]model.ShareLink, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + shareLinkColumns + ` FROM share_links
+		WHERE pdf_file_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		AND (max_downloads IS NULL OR download_count < max_downloads)
+		ORDER BY created_at DESC`
+	rows, err := r.DB.QueryContext(ctx, query, pdfID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []model.ShareLink{}
+	for rows.Next() {
+		l, err := scanShareLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *l)
+	}
+	return links, rows.Err()
+}
+
+// Revoke revokes a link of pdfID that is not revoked yet. Returns sql.ErrNoRows if there is none.
+func (r *ShareLinkRepository) Revoke(ctx context.Context, id, pdfID int64) (*model.ShareLink, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE share_links SET revoked_at = $1 WHERE id = $2 AND pdf_file_id = $3 AND revoked_at IS NULL RETURNING ` + shareLinkColumns
+	return scanShareLink(r.DB.QueryRowContext(ctx, query, time.Now(), id, pdfID))
+}
+
+// ClaimDownload counts one download if the link is still usable. Returns
+// false if it was revoked, expired or used up in the meantime, so concurrent
+// requests cannot exceed max_downloads.
+func (r *ShareLinkRepository) ClaimDownload(ctx context.Context, id int64, now time.Time) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE share_links
+		SET download_count = download_count + 1, last_accessed_at = $1
+		WHERE id = $2 AND revoked_at IS NULL AND expires_at > $1
+		AND (max_downloads IS NULL OR download_count < max_downloads)
+	`
+	res, err := r.DB.ExecContext(ctx, query, now, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// RecordFailedPassword counts a wrong password and revokes the link once
+// maxFailures is reached. Returns whether the link is now revoked.
+func (r *ShareLinkRepository) RecordFailedPassword(ctx context.Context, id int64, maxFailures int, now time.Time) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE share_links
+		SET failed_attempts = failed_attempts + 1, last_accessed_at = $1,
+			revoked_at = CASE WHEN failed_attempts + 1 >= $2 THEN COALESCE(revoked_at, $1) ELSE revoked_at END
+		WHERE id = $3
+		RETURNING revoked_at IS NOT NULL
+	`
+	var revoked bool
+	err := r.DB.QueryRowContext(ctx, query, now, maxFailures, id).Scan(&revoked)
+	return revoked, err
+}
+
+func (r *ShareLinkRepository) RecordAccess(ctx context.Context, a *model.ShareLinkAccess) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO share_link_accesses (share_link_id, ip_address, user_agent, outcome, accessed_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	return r.DB.QueryRowContext(ctx, query, a.ShareLinkID, a.IPAddress, a.UserAgent, a.Outcome, a.AccessedAt).Scan(&a.ID)
+}
+
+// FindAccesses returns the latest limit accesses of a link of pdfID, newest first.
+func (r *ShareLinkRepository) FindAccesses(ctx context.Context, id, pdfID int64, limit int) ([]model.ShareLinkAccess, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT a.id, a.share_link_id, COALESCE(a.ip_address, ''), COALESCE(a.user_agent, ''), a.outcome, a.accessed_at
+		FROM share_link_accesses a
+		JOIN share_links l ON l.id = a.share_link_id
+		WHERE a.share_link_id = $1 AND l.pdf_file_id = $2
+		ORDER BY a.accessed_at DESC, a.id DESC
+		LIMIT $3
+	`
+	rows, err := r.DB.QueryContext(ctx, query, id, pdfID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accesses := []model.ShareLinkAccess{}
+	for rows.Next() {
+		var a model.ShareLinkAccess
+		if err := rows.Scan(&a.ID, &a.ShareLinkID, &a.IPAddress, &a.UserAgent, &a.Outcome, &a.AccessedAt); err != nil {
+			return nil, err
+		}
+		accesses = append(accesses, a)
+	}
+	return accesses, rows.Err()
+}
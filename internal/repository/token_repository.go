@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"pdf-management-system/internal/model"
+	"time"
+)
+
+type TokenRepository struct {
+	DB *sql.DB
+}
+
+func NewTokenRepository(db *sql.DB) *TokenRepository {
+	return &TokenRepository{DB: db}
+}
+
+func (r *TokenRepository) CreateRefreshToken(ctx context.Context, t *model.RefreshToken) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO refresh_tokens (user_id, token_hash, family_id, created_at, expires_at, user_agent, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	return r.DB.QueryRowContext(ctx, query, t.UserID, t.TokenHash, t.FamilyID, t.CreatedAt, t.ExpiresAt, t.UserAgent, t.IPAddress).Scan(&t.ID)
+}
+
+func (r *TokenRepository) FindRefreshTokenByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, token_hash, family_id, created_at, expires_at, revoked_at, replaced_by, user_agent, ip_address
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+	var t model.RefreshToken
+	err := r.DB.QueryRowContext(ctx, query, hash).Scan(
+		&t.ID, &t.UserID, &t.TokenHash, &t.FamilyID, &t.CreatedAt, &t.ExpiresAt, &t.RevokedAt, &t.ReplacedBy, &t.UserAgent, &t.IPAddress,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RotateRefreshToken atomically revokes old and inserts next in its place.
+// Returns false if old was already revoked (a concurrent refresh won).
+func (r *TokenRepository) RotateRefreshToken(ctx context.Context, oldID int64, next *model.RefreshToken) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	insert := `
+		INSERT INTO refresh_tokens (user_id, token_hash, family_id, created_at, expires_at, user_agent, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	if err := tx.QueryRowContext(ctx, insert, next.UserID, next.TokenHash, next.FamilyID, next.CreatedAt, next.ExpiresAt, next.UserAgent, next.IPAddress).Scan(&next.ID); err != nil {
+		return false, err
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = $1, replaced_by = $2 WHERE id = $3 AND revoked_at IS NULL`, time.Now(), next.ID, oldID)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 1 {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *TokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`, time.Now(), familyID)
+	return err
+}
+
+// RevokeAllForUser revokes every refresh token and marks all access tokens
+// issued until now as invalid.
+func (r *TokenRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`, now, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET sessions_revoked_at = $1 WHERE id = $2`, now, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TokenRepository) RevokeJTI(ctx context.Context, jti string, userID int64, expiresAt time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (jti) DO NOTHING
+	`
+	_, err := r.DB.ExecContext(ctx, query, jti, userID, expiresAt, time.Now())
+	return err
+}
+
+// IsAccessTokenRevoked reports whether the jti was revoked or the token was
+// issued before the user's last "log out all sessions".
+func (r *TokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string, userID int64, issuedAt time.Time) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+			OR COALESCE((SELECT date_trunc('second', sessions_revoked_at) > $2 FROM users WHERE id = $3), FALSE)
+	`
+	var revoked bool
+	err := r.DB.QueryRowContext(ctx, query, jti, issuedAt, userID).Scan(&revoked)
+	return revoked, err
+}
+
+// DeleteExpired removes refresh tokens, reset tokens and revocation entries that can no longer be used.
+func (r *TokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	res1, err := r.DB.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := r.DB.ExecContext(ctx, `DELETE FROM password_reset_tokens WHERE expires_at < $1`, now); err != nil {
+		return 0, err
+	}
+	// replaced_by menunjuk ke baris lain, putuskan dulu sebelum dihapus
+	if _, err := r.DB.ExecContext(ctx, `UPDATE refresh_tokens SET replaced_by = NULL WHERE replaced_by IN (SELECT id FROM refresh_tokens WHERE expires_at < $1)`, now); err != nil {
+		return 0, err
+	}
+	res2, err := r.DB.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	n1, _ := res1.RowsAffected()
+	n2, _ := res2.RowsAffected()
+	return n1 + n2, nil
+}
+
+func (r *TokenRepository) CreatePasswordReset(ctx context.Context, userID int64, hash string, expiresAt time.Time, ip string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, created_at, expires_at, ip_address)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.DB.ExecContext(ctx, query, userID, hash, time.Now(), expiresAt, ip)
+	return err
+}
+
+// FindPasswordReset returns the user of an unused, unexpired reset token
+// without consuming it.
+func (r *TokenRepository) FindPasswordReset(ctx context.Context, hash string) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var userID int64
+	query := `
+		SELECT user_id FROM password_reset_tokens
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+	`
+	err := r.DB.QueryRowContext(ctx, query, hash, time.Now()).Scan(&userID)
+	return userID, err
+}
+
+// ConsumePasswordReset marks an unused, unexpired reset token as used and
+// returns its user. Other outstanding reset tokens of that user are invalidated too.
+func (r *TokenRepository) ConsumePasswordReset(ctx context.Context, hash string) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var userID int64
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1
+		RETURNING user_id
+	`
+	if err := tx.QueryRowContext(ctx, query, now, hash).Scan(&userID); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE password_reset_tokens SET used_at = $1 WHERE user_id = $2 AND used_at IS NULL`, now, userID); err != nil {
+		return 0, err
+	}
+
+	return userID, tx.Commit()
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"pdf-management-system/internal/model"
+	"time"
+)
+
+type UploadSessionRepository struct {
+	DB *sql.DB
+}
+
+func NewUploadSessionRepository(db *sql.DB) *UploadSessionRepository {
+	return &UploadSessionRepository{DB: db}
+}
+
+const uploadSessionColumns = `id, user_id, original_name, total_size, received_size, checksum, status, pdf_file_id, created_at, updated_at, expires_at`
+
+func scanUploadSession(row interface{ Scan(...interface{}) error }) (*model.UploadSession, error) {
+	var s model.UploadSession
+	err := row.Scan(&s.ID, &s.UserID, &s.OriginalName, &s.TotalSize, &s.Offset, &s.Checksum, &s.Status, &s.PdfFileID, &s.CreatedAt, &s.UpdatedAt, &s.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *UploadSessionRepository) Create(ctx context.Context, s *model.UploadSession) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		INSERT INTO upload_sessions (id, user_id, original_name, total_size, received_size, checksum, status, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, 0, $5, $6, $7, $8)
+	`
+	_, err := r.DB.ExecContext(ctx, query, s.ID, s.UserID, s.OriginalName, s.TotalSize, s.Checksum, s.Status, s.CreatedAt, s.ExpiresAt)
+	return err
+}
+
+func (r *UploadSessionRepository) FindByID(ctx context.Context, id string) (*model.UploadSession, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + uploadSessionColumns + ` FROM upload_sessions WHERE id = $1`
+	return scanUploadSession(r.DB.QueryRowContext(ctx, query, id))
+}
+
+// AdvanceOffset moves the offset forward only if it still equals `from`,
+// so two clients racing on the same session cannot both succeed.
+func (r *UploadSessionRepository) AdvanceOffset(ctx context.Context, id string, from, to int64, expiresAt time.Time) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE upload_sessions
+		SET received_size = $1, updated_at = $2, expires_at = $3
+		WHERE id = $4 AND received_size = $5 AND status = 'ACTIVE'
+	`
+	res, err := r.DB.ExecContext(ctx, query, to, time.Now(), expiresAt, id, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// UpdateStatus ends an ACTIVE session (abort, expiry). Sessions that were
+// completed in the meantime are left alone.
+func (r *UploadSessionRepository) UpdateStatus(ctx context.Context, id string, status model.UploadSessionStatus) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE upload_sessions SET status = $1, updated_at = $2 WHERE id = $3 AND status = 'ACTIVE'`
+	_, err := r.DB.ExecContext(ctx, query, status, time.Now(), id)
+	return err
+}
+
+// FindExpired returns active sessions whose expiry has passed.
+func (r *UploadSessionRepository) FindExpired(ctx context.Context, now time.Time) ([]model.UploadSession, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + uploadSessionColumns + ` FROM upload_sessions WHERE status = 'ACTIVE' AND expires_at < $1`
+	rows, err := r.DB.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []model.UploadSession
+	for rows.Next() {
+		s, err := scanUploadSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions, rows.Err()
+}
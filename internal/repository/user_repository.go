@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"pdf-management-system/internal/model"
 	"time"
 )
@@ -14,39 +16,199 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{DB: db}
 }
 
-func (r *UserRepository) CreateUser(user *model.User) error {
+// Kolom kontak boleh NULL (user lama / dibuat admin), jadi di-COALESCE
+const userColumns = `u.id, COALESCE(u.name, ''), u.email, COALESCE(u.password, ''), COALESCE(u.address, ''), COALESCE(u.phone_number, ''), COALESCE(u.post_code, ''),
+	COALESCE(u.role_id, 0), COALESCE(r.role, ''), u.is_email_verified, u.is_active, u.oidc_issuer, u.oidc_subject,
+	u.created_by, COALESCE(u.created_date, 'epoch'), u.modified_by, u.modified_date`
+
+const userFrom = `users u LEFT JOIN roles r ON r.id = u.role_id`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row rowScanner) (*model.User, error) {
+	var user model.User
+	err := row.Scan(
+		&user.ID, &user.Name, &user.Email, &user.Password, &user.Address, &user.PhoneNumber, &user.PostCode,
+		&user.RoleID, &user.RoleName, &user.IsEmailVerified, &user.IsActive, &user.OIDCIssuer, &user.OIDCSubject, &user.CreatedBy, &user.CreatedDate, &user.ModifiedBy, &user.ModifiedDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, user *model.User) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	query := `
-		INSERT INTO users (name, email, password, address, phone_number, post_code, role_id, is_email_verified, created_date)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO users (name, email, password, address, phone_number, post_code, role_id, is_email_verified, is_active, oidc_issuer, oidc_subject, created_by, created_date)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id
 	`
-	// Assuming default not verified, created_date is now
-	return r.DB.QueryRow(query, user.Name, user.Email, user.Password, user.Address, user.PhoneNumber, user.PostCode, user.RoleID, user.IsEmailVerified, time.Now()).Scan(&user.ID)
+	// created_by NULL berarti registrasi mandiri / SSO, password kosong berarti user SSO
+	return r.DB.QueryRowContext(ctx, query, user.Name, user.Email, user.Password, user.Address, user.PhoneNumber, user.PostCode, user.RoleID,
+		user.IsEmailVerified, user.IsActive, user.OIDCIssuer, user.OIDCSubject, user.CreatedBy, user.CreatedDate).Scan(&user.ID)
+}
+
+func (r *UserRepository) FindUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return scanUser(r.DB.QueryRowContext(ctx, `SELECT `+userColumns+` FROM `+userFrom+` WHERE u.email = $1`, email))
 }
 
-func (r *UserRepository) FindUserByEmail(email string) (*model.User, error) {
+func (r *UserRepository) FindUserByID(ctx context.Context, id int64) (*model.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return scanUser(r.DB.QueryRowContext(ctx, `SELECT `+userColumns+` FROM `+userFrom+` WHERE u.id = $1`, id))
+}
+
+func (r *UserRepository) FindUserByOIDC(ctx context.Context, issuer, subject string) (*model.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return scanUser(r.DB.QueryRowContext(ctx, `SELECT `+userColumns+` FROM `+userFrom+` WHERE u.oidc_issuer = $1 AND u.oidc_subject = $2`, issuer, subject))
+}
+
+// LinkOIDC attaches an identity provider account to an existing user.
+func (r *UserRepository) LinkOIDC(ctx context.Context, id int64, issuer, subject string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE users SET oidc_issuer = $1, oidc_subject = $2, is_email_verified = TRUE, modified_by = $3, modified_date = $4 WHERE id = $3`
+	_, err := r.DB.ExecContext(ctx, query, issuer, subject, id, time.Now())
+	return err
+}
+
+// FindAllUsers returns users ordered by id, optionally only active or inactive ones.
+func (r *UserRepository) FindAllUsers(ctx context.Context, active *bool, page, limit int) ([]model.User, int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	where := ""
+	args := []interface{}{}
+	if active != nil {
+		where = " WHERE u.is_active = $1"
+		args = append(args, *active)
+	}
+
+	var total int64
+	if err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM users u`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + userColumns + ` FROM ` + userFrom + where + fmt.Sprintf(" ORDER BY u.id ASC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, (page-1)*limit)
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, *user)
+	}
+	return users, total, rows.Err()
+}
+
+// UpdateUser saves the editable fields of user and stamps modified_by/modified_date.
+func (r *UserRepository) UpdateUser(ctx context.Context, user *model.User, modifiedBy int64) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
 	query := `
-		SELECT id, name, email, password, address, phone_number, post_code, role_id, is_email_verified, created_date
-		FROM users
-		WHERE email = $1
+		UPDATE users
+		SET name = $1, address = $2, phone_number = $3, post_code = $4, role_id = $5, is_active = $6, modified_by = $7, modified_date = $8
+		WHERE id = $9
 	`
-	var user model.User
-	err := r.DB.QueryRow(query, email).Scan(
-		&user.ID, &user.Name, &user.Email, &user.Password, &user.Address, &user.PhoneNumber, &user.PostCode, &user.RoleID, &user.IsEmailVerified, &user.CreatedDate,
-	)
+	res, err := r.DB.ExecContext(ctx, query, user.Name, user.Address, user.PhoneNumber, user.PostCode, user.RoleID, user.IsActive, modifiedBy, now, user.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	user.ModifiedBy = &modifiedBy
+	user.ModifiedDate = &now
+	return nil
+}
+
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id int64) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE users SET is_email_verified = TRUE, modified_by = $1, modified_date = $2 WHERE id = $1`
+	_, err := r.DB.ExecContext(ctx, query, id, time.Now())
+	return err
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int64, hashedPassword string, modifiedBy int64) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE users SET password = $1, modified_by = $2, modified_date = $3 WHERE id = $4`
+	_, err := r.DB.ExecContext(ctx, query, hashedPassword, modifiedBy, time.Now(), id)
+	return err
+}
+
+func (r *UserRepository) RoleExists(ctx context.Context, id int64) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+	err := r.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE id = $1)`, id).Scan(&exists)
+	return exists, err
+}
+
+func (r *UserRepository) FindRoleByName(ctx context.Context, name string) (*model.Role, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var role model.Role
+	err := r.DB.QueryRowContext(ctx, `SELECT id, role FROM roles WHERE role = $1`, name).Scan(&role.ID, &role.Role)
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	return &role, nil
 }
 
-// FindById needed for middleware probably
-func (r *UserRepository) FindUserByID(id int64) (*model.User, error) {
-	query := `SELECT id, name, email, role_id FROM users WHERE id = $1`
-	var user model.User
-	err := r.DB.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.Email, &user.RoleID)
+func (r *UserRepository) FindAllRoles(ctx context.Context) ([]model.Role, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, `SELECT id, role FROM roles ORDER BY id ASC`)
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	defer rows.Close()
+
+	roles := []model.Role{}
+	for rows.Next() {
+		var role model.Role
+		if err := rows.Scan(&role.ID, &role.Role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// CountActiveUsersWithRole is used to keep at least one active admin around.
+func (r *UserRepository) CountActiveUsersWithRole(ctx context.Context, roleID int64) (int64, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var n int64
+	err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE role_id = $1 AND is_active`, roleID).Scan(&n)
+	return n, err
 }
@@ -0,0 +1,109 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const clamdChunkSize = 64 << 10
+
+// ClamdScanner talks to a clamd daemon using the INSTREAM command.
+type ClamdScanner struct {
+	Network string // "tcp" or "unix"
+	Address string
+	Timeout time.Duration
+}
+
+// NewClamdScanner parses addresses of the form tcp://host:port or unix:///path.
+func NewClamdScanner(addr string) (*ClamdScanner, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid clamd address %q: %v", addr, err)
+	}
+
+	s := &ClamdScanner{Timeout: 2 * time.Minute}
+	switch u.Scheme {
+	case "tcp":
+		s.Network, s.Address = "tcp", u.Host
+	case "unix":
+		s.Network, s.Address = "unix", u.Path
+	default:
+		return nil, fmt.Errorf("invalid clamd address %q: scheme must be tcp or unix", addr)
+	}
+	return s, nil
+}
+
+func (s *ClamdScanner) Name() string { return "clamd" }
+
+func (s *ClamdScanner) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, s.Network, s.Address)
+	if err != nil {
+		return Result{}, fmt.Errorf("clamd: %v", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.Timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return Result{}, fmt.Errorf("clamd: %v", err)
+	}
+
+	// Format INSTREAM: <panjang 4 byte big-endian><data>, diakhiri chunk panjang 0
+	buf := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return Result{}, fmt.Errorf("clamd: %v", err)
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				// clamd menutup koneksi saat StreamMaxLength terlampaui, balasannya tetap dibaca
+				break
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return Result{}, readErr
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	conn.Write(size)
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return Result{}, fmt.Errorf("clamd: %v", err)
+	}
+	return parseClamdReply(reply)
+}
+
+// parseClamdReply handles "stream: OK", "stream: <sig> FOUND" and "<msg> ERROR".
+func parseClamdReply(reply string) (Result, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+
+	switch {
+	case strings.HasSuffix(reply, " OK"):
+		return Result{Clean: true}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		sig := strings.TrimSuffix(reply, " FOUND")
+		sig = strings.TrimSpace(strings.TrimPrefix(sig, "stream:"))
+		return Result{Clean: false, Signature: sig}, nil
+	default:
+		return Result{}, fmt.Errorf("clamd: unexpected reply %q", reply)
+	}
+}
@@ -0,0 +1,185 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeClamd is a local stand-in for clamd that records the INSTREAM chunks
+// it receives and answers with reply.
+type fakeClamd struct {
+	addr    string
+	command chan string
+	chunks  chan []int
+	data    chan []byte
+}
+
+func startFakeClamd(t *testing.T, reply string) *fakeClamd {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeClamd{
+		addr:    ln.Addr().String(),
+		command: make(chan string, 1),
+		chunks:  make(chan []int, 1),
+		data:    make(chan []byte, 1),
+	}
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		cmd := make([]byte, len("zINSTREAM\x00"))
+		if _, err := io.ReadFull(conn, cmd); err != nil {
+			return
+		}
+		f.command <- string(cmd)
+
+		var sizes []int
+		var data bytes.Buffer
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(conn, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			sizes = append(sizes, int(n))
+			if n == 0 {
+				break
+			}
+			if _, err := io.CopyN(&data, conn, int64(n)); err != nil {
+				return
+			}
+		}
+		f.chunks <- sizes
+		f.data <- data.Bytes()
+		conn.Write([]byte(reply + "\x00"))
+	}()
+	return f
+}
+
+func TestClamdScannerInstreamFraming(t *testing.T) {
+	clamd := startFakeClamd(t, "stream: OK")
+	s, err := NewClamdScanner("tcp://" + clamd.addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lebih dari dua chunk penuh supaya pembagian chunk ikut teruji
+	payload := bytes.Repeat([]byte("%PDF-1.4 "), (2*clamdChunkSize+1000)/9)
+	result, err := s.Scan(context.Background(), bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if !result.Clean {
+		t.Errorf("result = %+v, want clean", result)
+	}
+
+	if cmd := <-clamd.command; cmd != "zINSTREAM\x00" {
+		t.Errorf("command = %q, want zINSTREAM\\0", cmd)
+	}
+	sizes := <-clamd.chunks
+	if len(sizes) < 4 || sizes[len(sizes)-1] != 0 {
+		t.Fatalf("chunk sizes = %v, want several chunks ended by a zero-length chunk", sizes)
+	}
+	for _, n := range sizes[:len(sizes)-1] {
+		if n <= 0 || n > clamdChunkSize {
+			t.Errorf("chunk size %d outside 1..%d", n, clamdChunkSize)
+		}
+	}
+	if got := <-clamd.data; !bytes.Equal(got, payload) {
+		t.Errorf("clamd received %d bytes, want the %d byte payload", len(got), len(payload))
+	}
+}
+
+func TestClamdScannerReplies(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		clean     bool
+		signature string
+		wantErr   string
+	}{
+		{name: "clean", reply: "stream: OK", clean: true},
+		{name: "infected", reply: "stream: Eicar-Test-Signature FOUND", signature: "Eicar-Test-Signature"},
+		{name: "error", reply: "INSTREAM size limit exceeded. ERROR", wantErr: "unexpected reply"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clamd := startFakeClamd(t, tt.reply)
+			s, err := NewClamdScanner("tcp://" + clamd.addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := s.Scan(context.Background(), strings.NewReader("%PDF-1.4 test"))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if result.Clean != tt.clean || result.Signature != tt.signature {
+				t.Errorf("result = %+v, want clean=%v signature=%q", result, tt.clean, tt.signature)
+			}
+		})
+	}
+}
+
+func TestClamdScannerUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	s, err := NewClamdScanner("tcp://" + addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Scan(context.Background(), strings.NewReader("x")); err == nil {
+		t.Fatal("Scan succeeded without clamd, want error")
+	}
+}
+
+func TestNewClamdScannerAddress(t *testing.T) {
+	tests := []struct {
+		addr, network, address string
+		wantErr                bool
+	}{
+		{addr: "tcp://127.0.0.1:3310", network: "tcp", address: "127.0.0.1:3310"},
+		{addr: "unix:///run/clamav/clamd.ctl", network: "unix", address: "/run/clamav/clamd.ctl"},
+		{addr: "http://127.0.0.1:3310", wantErr: true},
+	}
+	for _, tt := range tests {
+		s, err := NewClamdScanner(tt.addr)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NewClamdScanner(%q) succeeded, want error", tt.addr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewClamdScanner(%q): %v", tt.addr, err)
+			continue
+		}
+		if s.Network != tt.network || s.Address != tt.address {
+			t.Errorf("NewClamdScanner(%q) = %s %s, want %s %s", tt.addr, s.Network, s.Address, tt.network, tt.address)
+		}
+	}
+}
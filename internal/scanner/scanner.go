@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"pdf-management-system/internal/config"
+)
+
+// Result is the verdict of a single scan.
+type Result struct {
+	Clean     bool
+	Signature string // name of the detected threat when not clean
+}
+
+// Scanner inspects file content for malware.
+type Scanner interface {
+	Name() string
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+}
+
+// NoopScanner reports every file as clean. Used when no scanner is configured.
+type NoopScanner struct{}
+
+func (NoopScanner) Name() string { return "noop" }
+
+func (NoopScanner) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	return Result{Clean: true}, nil
+}
+
+// New builds the scanner selected by cfg.Name (noop or clamd). clamd connects
+// to cfg.ClamdAddress, e.g. tcp://127.0.0.1:3310 or unix:///run/clamav/clamd.ctl.
+func New(cfg config.ScannerConfig) (Scanner, error) {
+	switch cfg.Name {
+	case "", "noop":
+		return NoopScanner{}, nil
+	case "clamd":
+		return NewClamdScanner(cfg.ClamdAddress)
+	default:
+		return nil, fmt.Errorf("unknown scanner %q", cfg.Name)
+	}
+}
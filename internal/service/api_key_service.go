@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/validation"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Prefix membuat key mudah dikenali (misal oleh secret scanner)
+const apiKeyPrefix = "pdfms_"
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid, revoked or expired API key")
+	ErrAPIKeyNotFound = errors.New("API key not found")
+)
+
+// APIKeyService manages long-lived API keys for service-to-service access.
+type APIKeyService struct {
+	Repo  APIKeyStore
+	Users UserStore
+}
+
+func NewAPIKeyService(repo APIKeyStore, users UserStore) *APIKeyService {
+	return &APIKeyService{Repo: repo, Users: users}
+}
+
+// Create issues a new key for userID. The plain key is only returned here.
+func (s *APIKeyService) Create(ctx context.Context, userID int64, req model.CreateAPIKeyRequest) (*model.CreatedAPIKey, error) {
+	now := time.Now()
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validation.ValidateCreateAPIKey(req, now).Err(); err != nil {
+		return nil, err
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	plain := apiKeyPrefix + secret
+
+	scopes := []string{}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	key := model.APIKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    plain[:len(apiKeyPrefix)+6],
+		KeyHash:   hashToken(plain),
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.Repo.Create(ctx, &key); err != nil {
+		return nil, err
+	}
+	return &model.CreatedAPIKey{APIKey: key, Key: plain}, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, userID int64) ([]model.APIKey, error) {
+	return s.Repo.FindByUser(ctx, userID)
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, userID, id int64) (*model.APIKey, error) {
+	key, err := s.Repo.Revoke(ctx, id, userID)
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, err
+}
+
+// ValidateAPIKey resolves a key to claims of its (active) owner.
+func (s *APIKeyService) ValidateAPIKey(ctx context.Context, plain string) (*model.TokenClaims, error) {
+	if !strings.HasPrefix(plain, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.Repo.FindByHash(ctx, hashToken(plain))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidAPIKey
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.Users.FindUserByID(ctx, key.UserID)
+	if err != nil || !user.IsActive {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.Repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+		slog.ErrorContext(ctx, "failed to update last_used_at of API key", "api_key_id", key.ID, "error", err)
+	}
+
+	return &model.TokenClaims{
+		UserID:        user.ID,
+		RoleID:        user.RoleID,
+		EmailVerified: user.IsEmailVerified,
+		APIKeyID:      key.ID,
+		Scopes:        key.Scopes,
+	}, nil
+}
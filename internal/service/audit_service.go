@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"pdf-management-system/internal/model"
+	"time"
+)
+
+const maxAuditPageSize = 100
+
+type AuditService struct {
+	Repo AuditStore
+}
+
+func NewAuditService(repo AuditStore) *AuditService {
+	return &AuditService{Repo: repo}
+}
+
+// Record saves one event. Failing to write the audit log never fails the
+// request itself, the error is only logged.
+func (s *AuditService) Record(ctx context.Context, e model.AuditEvent) {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	if err := s.Repo.Create(ctx, &e); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit event", "action", e.Action, "status", e.StatusCode, "error", err)
+	}
+}
+
+func (s *AuditService) List(ctx context.Context, f model.AuditFilter) ([]model.AuditEvent, int64, model.AuditFilter, error) {
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.Limit < 1 {
+		f.Limit = 20
+	}
+	if f.Limit > maxAuditPageSize {
+		f.Limit = maxAuditPageSize
+	}
+	events, total, err := s.Repo.FindAll(ctx, f)
+	return events, total, f, err
+}
+
+// Export streams every event matching f to fn, oldest first.
+func (s *AuditService) Export(ctx context.Context, f model.AuditFilter, fn func(*model.AuditEvent) error) error {
+	return s.Repo.Each(ctx, f, fn)
+}
@@ -1,29 +1,93 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"os"
+	"log/slog"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/mailer"
 	"pdf-management-system/internal/model"
-	"pdf-management-system/internal/repository"
+	"pdf-management-system/internal/signing"
+	"pdf-management-system/internal/validation"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, all sessions in this family were revoked")
+	ErrTokenRevoked        = errors.New("token has been revoked")
+)
+
 type AuthService struct {
-	Repo *repository.UserRepository
+	Repo   UserStore
+	Tokens TokenStore
+	Mailer mailer.Mailer
+	Keys   *signing.KeySet
+	Guard  *LoginGuard // optional brute-force protection
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	DefaultRole     string // role for self-registration and new SSO users
+
+	// Password reset, see password_reset.go
+	PasswordResetTTL time.Duration
+	PasswordResetURL string // frontend page that accepts ?token=, optional
+
+	// Email verification, see email_verification.go
+	VerificationMode VerificationMode
+	VerificationTTL  time.Duration
+	BaseURL          string
+	resendMu         sync.Mutex
+	lastResend       map[string]time.Time
 }
 
-func NewAuthService(repo *repository.UserRepository) *AuthService {
-	return &AuthService{Repo: repo}
+func NewAuthService(repo UserStore, tokens TokenStore, m mailer.Mailer, keys *signing.KeySet, guard *LoginGuard, cfg *config.Config) *AuthService {
+	return &AuthService{
+		Repo:             repo,
+		Tokens:           tokens,
+		Mailer:           m,
+		Keys:             keys,
+		Guard:            guard,
+		AccessTokenTTL:   cfg.JWT.AccessTokenTTL,
+		RefreshTokenTTL:  cfg.JWT.RefreshTokenTTL,
+		DefaultRole:      cfg.Auth.DefaultRole,
+		PasswordResetTTL: cfg.Auth.PasswordResetTTL,
+		PasswordResetURL: cfg.Auth.PasswordResetURL,
+		VerificationMode: VerificationMode(cfg.Auth.EmailVerification),
+		VerificationTTL:  cfg.Auth.VerificationTTL,
+		BaseURL:          cfg.Server.BaseURL,
+		lastResend:       make(map[string]time.Time),
+	}
 }
 
-func (s *AuthService) Register(req model.RegisterRequest) (*model.User, error) {
+func (s *AuthService) Register(ctx context.Context, req model.RegisterRequest) (*model.User, error) {
+	req.Email = strings.TrimSpace(req.Email)
+	errs := validation.ValidateRegister(req)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	// Registrasi mandiri selalu mendapat role default (DEFAULT_ROLE), role lain diatur admin
+	role, err := s.Repo.FindRoleByName(ctx, s.DefaultRole)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load default role", "role", s.DefaultRole, "error", err)
+		return nil, ErrDefaultRoleNotFound
+	}
+
 	// Check if email exists
-	existing, _ := s.Repo.FindUserByEmail(req.Email)
+	existing, _ := s.Repo.FindUserByEmail(ctx, req.Email)
 	if existing != nil {
-		return nil, errors.New("email already registered")
+		errs.Add("email", validation.CodeAlreadyExists, "email already registered")
+		return nil, errs
 	}
 
 	// Hash password
@@ -39,52 +103,289 @@ func (s *AuthService) Register(req model.RegisterRequest) (*model.User, error) {
 		Address:         req.Address,
 		PhoneNumber:     req.PhoneNumber,
 		PostCode:        req.PostCode,
-		RoleID:          req.RoleID,
+		RoleID:          role.ID,
 		IsEmailVerified: false,
+		IsActive:        true,
 		CreatedDate:     time.Now(),
 	}
 
-	if err := s.Repo.CreateUser(user); err != nil {
+	if err := s.Repo.CreateUser(ctx, user); err != nil {
 		return nil, err
 	}
 
+	// Gagal kirim email tidak menggagalkan registrasi, user bisa minta kirim ulang
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		slog.ErrorContext(ctx, "failed to send verification email", "target_user_id", user.ID, "error", err)
+	}
+
 	return user, nil
 }
 
-func (s *AuthService) Login(req model.LoginRequest) (*model.AuthResponse, error) {
-	user, err := s.Repo.FindUserByEmail(req.Email)
+func (s *AuthService) Login(ctx context.Context, req model.LoginRequest, client model.ClientInfo) (*model.AuthResponse, error) {
+	if err := validation.ValidateLogin(req).Err(); err != nil {
+		return nil, err
+	}
+
+	if s.Guard != nil {
+		if err := s.Guard.Check(ctx, req.Email, client.IP); err != nil {
+			if errors.Is(err, ErrLoginLocked) {
+				s.Guard.Locked(ctx, req.Email, client)
+			}
+			return nil, err
+		}
+	}
+
+	user, err := s.Repo.FindUserByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, s.loginFailed(ctx, req.Email, nil, client)
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, s.loginFailed(ctx, req.Email, &user.ID, client)
+	}
+
+	if s.Guard != nil {
+		if err := s.Guard.Success(ctx, req.Email); err != nil {
+			slog.ErrorContext(ctx, "failed to reset login attempts", "error", err)
+		}
+	}
+
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+	if s.VerificationMode == VerifyForLogin && !user.IsEmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	familyID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueSession(ctx, user, familyID, client, 0)
+}
+
+// loginFailed counts the failure and returns the error for the client: the
+// lockout if this attempt triggered one, otherwise the generic message.
+func (s *AuthService) loginFailed(ctx context.Context, email string, userID *int64, client model.ClientInfo) error {
+	if s.Guard != nil {
+		if err := s.Guard.Failure(ctx, email, userID, client); err != nil {
+			if errors.Is(err, ErrLoginLocked) {
+				return err
+			}
+			slog.ErrorContext(ctx, "failed to record login failure", "error", err)
+		}
+	}
+	return errors.New("invalid email or password")
+}
+
+// Refresh exchanges a refresh token for a new access/refresh pair. The old
+// refresh token is revoked; presenting it again revokes the whole family.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string, client model.ClientInfo) (*model.AuthResponse, error) {
+	stored, err := s.Tokens.FindRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.RevokedAt != nil {
+		if stored.ReplacedBy != nil {
+			// Token lama dipakai lagi: kemungkinan dicuri, cabut semua turunannya
+			if err := s.Tokens.RevokeFamily(ctx, stored.FamilyID); err != nil {
+				return nil, err
+			}
+			return nil, ErrRefreshTokenReused
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.Repo.FindUserByID(ctx, stored.UserID)
+	if err != nil || !user.IsActive {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.issueSession(ctx, user, stored.FamilyID, client, stored.ID)
+}
+
+// Logout revokes the presented access token and, if given, its refresh token family.
+func (s *AuthService) Logout(ctx context.Context, claims *model.TokenClaims, refreshToken string) error {
+	if refreshToken != "" {
+		stored, err := s.Tokens.FindRefreshTokenByHash(ctx, hashToken(refreshToken))
+		if err == nil && stored.UserID == claims.UserID {
+			if err := s.Tokens.RevokeFamily(ctx, stored.FamilyID); err != nil {
+				return err
+			}
+		}
+	}
+	return s.Tokens.RevokeJTI(ctx, claims.JTI, claims.UserID, claims.ExpiresAt)
+}
+
+// LogoutAll revokes every session of the user, including the current one.
+func (s *AuthService) LogoutAll(ctx context.Context, userID int64) error {
+	return s.Tokens.RevokeAllForUser(ctx, userID)
+}
+
+// ValidateAccessToken verifies signature, expiry and revocation of an access token.
+func (s *AuthService) ValidateAccessToken(ctx context.Context, tokenString string) (*model.TokenClaims, error) {
+	token, err := s.Keys.Parse(tokenString, jwt.WithExpirationRequired(), jwt.WithIssuedAt())
+	// Token dengan tujuan lain (misal verifikasi email) tidak boleh dipakai sebagai access token
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || mapClaims["typ"] != "access" {
+		return nil, errors.New("invalid token claims")
+	}
+
+	claims := &model.TokenClaims{}
+	userID, ok1 := mapClaims["user_id"].(float64)
+	roleID, ok2 := mapClaims["role_id"].(float64)
+	jti, ok3 := mapClaims["jti"].(string)
+	if !ok1 || !ok2 || !ok3 || jti == "" {
+		return nil, errors.New("invalid token claims")
+	}
+	claims.UserID = int64(userID)
+	claims.RoleID = int64(roleID)
+	claims.JTI = jti
+	claims.EmailVerified, _ = mapClaims["email_verified"].(bool)
+	if iat, err := mapClaims.GetIssuedAt(); err == nil && iat != nil {
+		claims.IssuedAt = iat.Time
+	}
+	if exp, err := mapClaims.GetExpirationTime(); err == nil && exp != nil {
+		claims.ExpiresAt = exp.Time
+	}
+
+	revoked, err := s.Tokens.IsAccessTokenRevoked(ctx, claims.JTI, claims.UserID, claims.IssuedAt)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+// PurgeExpiredTokens deletes refresh tokens and revocation entries past their expiry.
+func (s *AuthService) PurgeExpiredTokens(ctx context.Context) (int64, error) {
+	if s.Guard != nil {
+		if err := s.Guard.Purge(ctx); err != nil {
+			slog.Error("failed to purge login attempt counters", "error", err)
+		}
+	}
+	return s.Tokens.DeleteExpired(ctx, time.Now())
+}
+
+// RunTokenCleanupLoop calls PurgeExpiredTokens every interval until ctx is done.
+func (s *AuthService) RunTokenCleanupLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.PurgeExpiredTokens(ctx); err != nil {
+				slog.Error("failed to purge expired tokens", "error", err)
+			}
+		}
+	}
+}
+
+// issueSession creates an access token and a refresh token in familyID.
+// When replacing > 0 the refresh token with that ID is rotated out atomically.
+func (s *AuthService) issueSession(ctx context.Context, user *model.User, familyID string, client model.ClientInfo, replacing int64) (*model.AuthResponse, error) {
+	accessToken, err := s.generateJWT(user)
+	if err != nil {
+		return nil, err
 	}
 
-	// Generate Token
-	token, err := s.generateJWT(user)
+	refreshToken, err := randomToken(32)
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
+	record := &model.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(refreshToken),
+		FamilyID:  familyID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.RefreshTokenTTL),
+	}
+	if client.UserAgent != "" {
+		ua := truncateRunes(client.UserAgent, 255)
+		record.UserAgent = &ua
+	}
+	if client.IP != "" {
+		record.IPAddress = &client.IP
+	}
+
+	if replacing > 0 {
+		ok, err := s.Tokens.RotateRefreshToken(ctx, replacing, record)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrInvalidRefreshToken
+		}
+	} else if err := s.Tokens.CreateRefreshToken(ctx, record); err != nil {
+		return nil, err
+	}
+
 	return &model.AuthResponse{
-		Token: token,
-		User:  *user,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.AccessTokenTTL.Seconds()),
+		User:         *user,
 	}, nil
 }
 
 func (s *AuthService) generateJWT(user *model.User) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "default_secret"
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
 	}
 
+	now := time.Now()
 	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"role_id": user.RoleID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+		"typ":            "access",
+		"user_id":        user.ID,
+		"role_id":        user.RoleID,
+		"email_verified": user.IsEmailVerified,
+		"jti":            jti,
+		"iat":            now.Unix(),
+		"exp":            now.Add(s.AccessTokenTTL).Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return s.Keys.Sign(claims)
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// truncateRunes cuts s to at most n characters without splitting a UTF-8
+// sequence, for VARCHAR(n) columns.
+func truncateRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+// Refresh token hanya disimpan dalam bentuk hash
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
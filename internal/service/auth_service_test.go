@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/signing"
+	"pdf-management-system/internal/validation"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// newAuthService returns an AuthService on empty fake stores.
+func newAuthService(t *testing.T) *AuthService {
+	t.Helper()
+	keys, err := signing.NewKeySet(signing.HMACKey("test-secret-at-least-32-bytes-long"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &AuthService{Repo: newFakeUserStore(), Tokens: newFakeTokenStore(), Keys: keys, AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour}
+}
+
+func newTestAuthService(t *testing.T) (*AuthService, *fakeTokenStore, *model.User) {
+	t.Helper()
+	s := newAuthService(t)
+	user := &model.User{Name: "Ana", Email: "ana@example.com", RoleID: 2, IsActive: true, IsEmailVerified: true}
+	if err := s.Repo.CreateUser(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return s, s.Tokens.(*fakeTokenStore), user
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+	s, tokens, user := newTestAuthService(t)
+	ctx := context.Background()
+	first, err := s.issueSession(ctx, user, "family-a", model.ClientInfo{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := s.Refresh(ctx, first.RefreshToken, model.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("refresh token was not rotated")
+	}
+	if _, err := s.ValidateAccessToken(ctx, second.Token); err != nil {
+		t.Errorf("new access token rejected: %v", err)
+	}
+
+	old, _ := tokens.FindRefreshTokenByHash(ctx, hashToken(first.RefreshToken))
+	next, _ := tokens.FindRefreshTokenByHash(ctx, hashToken(second.RefreshToken))
+	if old.RevokedAt == nil || old.ReplacedBy == nil || *old.ReplacedBy != next.ID {
+		t.Errorf("old token = revoked %v replaced by %v, want revoked and replaced by %d", old.RevokedAt, old.ReplacedBy, next.ID)
+	}
+	if next.FamilyID != "family-a" {
+		t.Errorf("family = %q, want family-a", next.FamilyID)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	s, _, user := newTestAuthService(t)
+	ctx := context.Background()
+	stolen, _ := s.issueSession(ctx, user, "family-a", model.ClientInfo{}, 0)
+	other, _ := s.issueSession(ctx, user, "family-b", model.ClientInfo{}, 0)
+
+	// Pemilik sah melakukan refresh, lalu token lama dipakai lagi oleh pencuri
+	legit, err := s.Refresh(ctx, stolen.RefreshToken, model.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Refresh(ctx, stolen.RefreshToken, model.ClientInfo{}); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reused token: err = %v, want ErrRefreshTokenReused", err)
+	}
+
+	// Seluruh keluarga dicabut, termasuk token terbaru milik pemilik sah
+	if _, err := s.Refresh(ctx, legit.RefreshToken, model.ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("latest token of the family: err = %v, want ErrInvalidRefreshToken", err)
+	}
+	// Sesi lain milik user yang sama tidak terpengaruh
+	if _, err := s.Refresh(ctx, other.RefreshToken, model.ClientInfo{}); err != nil {
+		t.Errorf("token of another family: %v", err)
+	}
+}
+
+func TestRefreshRejects(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unknown", func(t *testing.T) {
+		s, _, _ := newTestAuthService(t)
+		if _, err := s.Refresh(ctx, "not-a-token", model.ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("err = %v, want ErrInvalidRefreshToken", err)
+		}
+	})
+
+	t.Run("logged out", func(t *testing.T) {
+		s, tokens, user := newTestAuthService(t)
+		session, _ := s.issueSession(ctx, user, "family-a", model.ClientInfo{}, 0)
+		tokens.RevokeFamily(ctx, "family-a")
+		// Dicabut tanpa pengganti bukan indikasi pencurian
+		if _, err := s.Refresh(ctx, session.RefreshToken, model.ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("err = %v, want ErrInvalidRefreshToken", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		s, _, user := newTestAuthService(t)
+		s.RefreshTokenTTL = -time.Second
+		session, _ := s.issueSession(ctx, user, "family-a", model.ClientInfo{}, 0)
+		if _, err := s.Refresh(ctx, session.RefreshToken, model.ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("err = %v, want ErrInvalidRefreshToken", err)
+		}
+	})
+
+	t.Run("deactivated user", func(t *testing.T) {
+		s, _, user := newTestAuthService(t)
+		session, _ := s.issueSession(ctx, user, "family-a", model.ClientInfo{}, 0)
+		user.IsActive = false
+		s.Repo.UpdateUser(ctx, user, 1)
+		if _, err := s.Refresh(ctx, session.RefreshToken, model.ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("err = %v, want ErrInvalidRefreshToken", err)
+		}
+	})
+}
+
+func TestTruncateRunes(t *testing.T) {
+	if got := truncateRunes("héllo", 2); got != "hé" {
+		t.Errorf("truncateRunes = %q, want hé", got)
+	}
+	if got := truncateRunes("héllo", 10); got != "héllo" {
+		t.Errorf("truncateRunes = %q, want héllo", got)
+	}
+
+	// Header sebesar MaxHeaderBytes harus tetap cepat (satu kali lewat)
+	long := strings.Repeat("日本語", 1<<18)
+	got := truncateRunes(long, 255)
+	if !utf8.ValidString(got) || utf8.RuneCountInString(got) != 255 || !strings.HasPrefix(long, got) {
+		t.Errorf("truncateRunes of %d bytes = %d runes, valid %v", len(long), utf8.RuneCountInString(got), utf8.ValidString(got))
+	}
+}
+
+func TestLoginRejectsLongEmail(t *testing.T) {
+	s := newAuthService(t)
+	req := model.LoginRequest{Email: strings.Repeat("é", validation.MaxEmailLength) + "@example.com", Password: "secret123"}
+	_, err := s.Login(context.Background(), req, model.ClientInfo{})
+	var errs validation.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Login error = %v, want validation error", err)
+	}
+}
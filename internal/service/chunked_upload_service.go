@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/model"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUploadNotFound    = errors.New("upload not found")
+	ErrUploadNotActive   = errors.New("upload is no longer active")
+	ErrUploadTooLarge    = errors.New("file size exceeds maximum limit for your role")
+	ErrUploadIncomplete  = errors.New("upload is incomplete")
+	ErrChunkTooLarge     = errors.New("chunk exceeds maximum chunk size or remaining file size")
+	ErrEmptyChunk        = errors.New("chunk is empty")
+	ErrOffsetMismatch    = errors.New("chunk offset does not match upload offset")
+	ErrChecksumMismatch  = errors.New("checksum mismatch")
+	ErrInvalidUploadName = errors.New("only files with .pdf extension are allowed")
+	ErrNotPdfContent     = errors.New("file content is not a PDF")
+)
+
+type ChunkedUploadService struct {
+	Repo UploadSessionStore
+	Pdf  *PdfService
+
+	TmpDir         string
+	ChunkSize      int64
+	SessionTTL     time.Duration
+	DefaultMaxSize int64
+	RoleMaxSize    map[int64]int64
+
+	locks keyedMutex
+}
+
+func NewChunkedUploadService(repo UploadSessionStore, pdf *PdfService, cfg *config.Config) *ChunkedUploadService {
+	roleMaxSize := cfg.Upload.MaxSizeByRole
+	if roleMaxSize == nil {
+		roleMaxSize = make(map[int64]int64)
+	}
+	return &ChunkedUploadService{
+		Repo:           repo,
+		Pdf:            pdf,
+		TmpDir:         cfg.Storage.UploadTmpDir,
+		ChunkSize:      cfg.Upload.ChunkSize,
+		SessionTTL:     cfg.Upload.SessionTTL,
+		DefaultMaxSize: cfg.Upload.MaxSize,
+		RoleMaxSize:    roleMaxSize,
+	}
+}
+
+// MaxSizeForRole returns the upload size limit for the given role.
+func (s *ChunkedUploadService) MaxSizeForRole(roleID int64) int64 {
+	if size, ok := s.RoleMaxSize[roleID]; ok {
+		return size
+	}
+	return s.DefaultMaxSize
+}
+
+func (s *ChunkedUploadService) Init(ctx context.Context, userID, roleID int64, req model.InitUploadRequest) (*model.UploadSession, error) {
+	if strings.ToLower(filepath.Ext(req.Filename)) != ".pdf" || len(req.Filename) > 255 {
+		return nil, ErrInvalidUploadName
+	}
+	if req.Size <= 0 || req.Size > s.MaxSizeForRole(roleID) {
+		return nil, ErrUploadTooLarge
+	}
+
+	id, err := newUploadID()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(s.TmpDir, 0700); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sess := &model.UploadSession{
+		ID:           id,
+		UserID:       userID,
+		OriginalName: filepath.Base(req.Filename),
+		TotalSize:    req.Size,
+		Status:       model.UploadActive,
+		ChunkSize:    s.ChunkSize,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(s.SessionTTL),
+	}
+	if req.Checksum != "" {
+		checksum := strings.ToLower(strings.TrimPrefix(req.Checksum, "sha256="))
+		sess.Checksum = &checksum
+	}
+
+	if err := s.Repo.Create(ctx, sess); err != nil {
+		return nil, err
+	}
+
+	return sess, nil
+}
+
+func (s *ChunkedUploadService) Status(ctx context.Context, userID int64, id string) (*model.UploadSession, error) {
+	return s.find(ctx, userID, id)
+}
+
+// WriteChunk appends body at offset. On offset mismatch the current session is
+// returned alongside ErrOffsetMismatch so the client can resume from it.
+func (s *ChunkedUploadService) WriteChunk(ctx context.Context, userID int64, id string, offset int64, checksum string, body io.Reader) (*model.UploadSession, error) {
+	unlock := s.locks.Lock(id)
+	defer unlock()
+
+	sess, err := s.findActive(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if offset != sess.Offset {
+		return sess, ErrOffsetMismatch
+	}
+
+	f, err := os.OpenFile(s.partPath(id), os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Buang sisa chunk gagal sebelumnya yang tidak tercatat di DB
+	if err := f.Truncate(sess.Offset); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(sess.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	limit := s.ChunkSize
+	if remaining := sess.TotalSize - sess.Offset; remaining < limit {
+		limit = remaining
+	}
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(body, limit+1))
+	if err == nil && n > limit {
+		err = ErrChunkTooLarge
+	}
+	if err == nil && n == 0 {
+		err = ErrEmptyChunk
+	}
+	if err == nil && checksum != "" {
+		expected := strings.ToLower(strings.TrimPrefix(checksum, "sha256="))
+		if hex.EncodeToString(h.Sum(nil)) != expected {
+			err = ErrChecksumMismatch
+		}
+	}
+	if err != nil {
+		f.Truncate(sess.Offset)
+		return nil, err
+	}
+
+	if err := f.Sync(); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.SessionTTL)
+	ok, err := s.Repo.AdvanceOffset(ctx, id, sess.Offset, sess.Offset+n, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrUploadNotActive
+	}
+
+	sess.Offset += n
+	sess.ExpiresAt = expiresAt
+	return sess, nil
+}
+
+// Complete verifies the assembled file and moves it into PDF storage. The
+// session is completed in the same transaction as the pdf_files insert;
+// calling Complete again on a completed session returns the same file.
+func (s *ChunkedUploadService) Complete(ctx context.Context, userID int64, id string) (*model.PdfFile, error) {
+	unlock := s.locks.Lock(id)
+	defer unlock()
+
+	sess, err := s.find(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	// Retry setelah response sebelumnya hilang
+	if sess.Status == model.UploadCompleted && sess.PdfFileID != nil {
+		return s.Pdf.Repo.FindByID(ctx, *sess.PdfFileID)
+	}
+	if sess.Status != model.UploadActive || time.Now().After(sess.ExpiresAt) {
+		return nil, ErrUploadNotActive
+	}
+	if sess.Offset != sess.TotalSize {
+		return nil, ErrUploadIncomplete
+	}
+
+	partPath := s.partPath(id)
+	if err := verifyAssembledFile(partPath, sess.Checksum); err != nil {
+		return nil, err
+	}
+
+	pdf, err := s.Pdf.StoreUpload(ctx, partPath, &model.PdfFile{OriginalName: &sess.OriginalName}, id)
+	if err == sql.ErrNoRows {
+		// Sesi di-abort atau kedaluwarsa oleh proses lain
+		return nil, ErrUploadNotActive
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return pdf, nil
+}
+
+func (s *ChunkedUploadService) Abort(ctx context.Context, userID int64, id string) error {
+	unlock := s.locks.Lock(id)
+	defer unlock()
+
+	if _, err := s.findActive(ctx, userID, id); err != nil {
+		return err
+	}
+	os.Remove(s.partPath(id))
+	return s.Repo.UpdateStatus(ctx, id, model.UploadAborted)
+}
+
+// ExpireAbandoned removes partial files of sessions past their expiry.
+func (s *ChunkedUploadService) ExpireAbandoned(ctx context.Context) (int, error) {
+	sessions, err := s.Repo.FindExpired(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, sess := range sessions {
+		// Jangan bentrok dengan Complete yang sedang berjalan untuk sesi ini
+		unlock := s.locks.Lock(sess.ID)
+		os.Remove(s.partPath(sess.ID))
+		err := s.Repo.UpdateStatus(ctx, sess.ID, model.UploadExpired)
+		unlock()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(sessions), nil
+}
+
+// RunExpiryLoop calls ExpireAbandoned every interval until ctx is done.
+func (s *ChunkedUploadService) RunExpiryLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.ExpireAbandoned(ctx)
+			if err != nil {
+				slog.Error("failed to expire abandoned uploads", "error", err)
+			} else if n > 0 {
+				slog.Info("expired abandoned uploads", "count", n)
+			}
+		}
+	}
+}
+
+func (s *ChunkedUploadService) find(ctx context.Context, userID int64, id string) (*model.UploadSession, error) {
+	sess, err := s.Repo.FindByID(ctx, id)
+	if err == sql.ErrNoRows {
+		return nil, ErrUploadNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	// Sesi milik user lain diperlakukan seperti tidak ada
+	if sess.UserID != userID {
+		return nil, ErrUploadNotFound
+	}
+	sess.ChunkSize = s.ChunkSize
+	return sess, nil
+}
+
+func (s *ChunkedUploadService) findActive(ctx context.Context, userID int64, id string) (*model.UploadSession, error) {
+	sess, err := s.find(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if sess.Status != model.UploadActive || time.Now().After(sess.ExpiresAt) {
+		return nil, ErrUploadNotActive
+	}
+	return sess, nil
+}
+
+func (s *ChunkedUploadService) partPath(id string) string {
+	return filepath.Join(s.TmpDir, id+".part")
+}
+
+func verifyAssembledFile(path string, checksum *string) error {
+	if err := checkPdfMagic(path); err != nil {
+		return err
+	}
+	if checksum == nil {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != *checksum {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
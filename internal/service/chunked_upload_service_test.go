@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"pdf-management-system/internal/model"
+	"strings"
+	"testing"
+)
+
+const chunkedContent = "%PDF-1.4 chunked upload test"
+
+type chunkedFixture struct {
+	svc      *ChunkedUploadService
+	uploads  *fakeUploadStore
+	pdfs     *fakePdfStore
+	pdfDir   string
+	checksum string
+}
+
+func newChunkedFixture(t *testing.T) *chunkedFixture {
+	t.Helper()
+	cfg := testConfig(t.TempDir())
+	cfg.Upload.ChunkSize = 10
+	if err := os.MkdirAll(cfg.Storage.PdfDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	pdfs, uploads := newFakePdfStore(), newFakeUploadStore()
+	pdfs.onUpload = uploads.complete
+	sum := sha256.Sum256([]byte(chunkedContent))
+	return &chunkedFixture{
+		svc:      NewChunkedUploadService(uploads, NewPdfService(pdfs, nil, cfg), cfg),
+		uploads:  uploads,
+		pdfs:     pdfs,
+		pdfDir:   cfg.Storage.PdfDir,
+		checksum: hex.EncodeToString(sum[:]),
+	}
+}
+
+// upload creates a session for userID and sends content in ChunkSize chunks.
+func (f *chunkedFixture) upload(t *testing.T, userID int64, content string) *model.UploadSession {
+	t.Helper()
+	ctx := context.Background()
+	sess, err := f.svc.Init(ctx, userID, 2, model.InitUploadRequest{Filename: "laporan.pdf", Size: int64(len(chunkedContent)), Checksum: f.checksum})
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	for off := 0; off < len(content); off += int(f.svc.ChunkSize) {
+		end := min(off+int(f.svc.ChunkSize), len(content))
+		if sess, err = f.svc.WriteChunk(ctx, userID, sess.ID, int64(off), "", strings.NewReader(content[off:end])); err != nil {
+			t.Fatalf("WriteChunk at %d: %v", off, err)
+		}
+	}
+	return sess
+}
+
+func (f *chunkedFixture) storedFiles(t *testing.T) []string {
+	t.Helper()
+	entries, err := os.ReadDir(f.pdfDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestChunkedCompleteStoresFileAndCompletesSession(t *testing.T) {
+	f := newChunkedFixture(t)
+	ctx := context.Background()
+	sess := f.upload(t, 7, chunkedContent)
+
+	pdf, err := f.svc.Complete(ctx, 7, sess.ID)
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if pdf.OriginalName == nil || *pdf.OriginalName != "laporan.pdf" || pdf.Size != int64(len(chunkedContent)) {
+		t.Errorf("stored record = %+v", pdf)
+	}
+	data, err := os.ReadFile(filepath.Join(f.pdfDir, pdf.Filename))
+	if err != nil || string(data) != chunkedContent {
+		t.Errorf("stored file = %q, %v", data, err)
+	}
+	if _, err := os.Stat(f.svc.partPath(sess.ID)); !os.IsNotExist(err) {
+		t.Errorf("part file still present: %v", err)
+	}
+
+	stored, _ := f.uploads.FindByID(ctx, sess.ID)
+	if stored.Status != model.UploadCompleted || stored.PdfFileID == nil || *stored.PdfFileID != pdf.ID {
+		t.Errorf("session = %s (pdf %v), want COMPLETED with pdf %d", stored.Status, stored.PdfFileID, pdf.ID)
+	}
+
+	// Retry setelah response hilang: file yang sama, tanpa row baru
+	again, err := f.svc.Complete(ctx, 7, sess.ID)
+	if err != nil || again.ID != pdf.ID {
+		t.Fatalf("retried Complete = %v, %v; want pdf %d", again, err, pdf.ID)
+	}
+	if len(f.pdfs.files) != 1 || len(f.storedFiles(t)) != 1 {
+		t.Errorf("retry created %d rows and %d files, want 1 each", len(f.pdfs.files), len(f.storedFiles(t)))
+	}
+}
+
+func TestChunkedCompleteFailedCommitCanBeRetried(t *testing.T) {
+	f := newChunkedFixture(t)
+	ctx := context.Background()
+	sess := f.upload(t, 7, chunkedContent)
+
+	f.pdfs.failCommit = errors.New("commit failed")
+	if _, err := f.svc.Complete(ctx, 7, sess.ID); err == nil {
+		t.Fatal("Complete succeeded, want the commit error")
+	}
+
+	stored, _ := f.uploads.FindByID(ctx, sess.ID)
+	if stored.Status != model.UploadActive || stored.PdfFileID != nil {
+		t.Errorf("session after failed commit = %s (pdf %v), want ACTIVE", stored.Status, stored.PdfFileID)
+	}
+	if files := f.storedFiles(t); len(files) != 0 {
+		t.Errorf("files left in storage after failed commit: %v", files)
+	}
+	if data, err := os.ReadFile(f.svc.partPath(sess.ID)); err != nil || string(data) != chunkedContent {
+		t.Fatalf("part file not restored: %q, %v", data, err)
+	}
+
+	pdf, err := f.svc.Complete(ctx, 7, sess.ID)
+	if err != nil {
+		t.Fatalf("Complete after failed commit: %v", err)
+	}
+	if stored, _ := f.uploads.FindByID(ctx, sess.ID); stored.Status != model.UploadCompleted || *stored.PdfFileID != pdf.ID {
+		t.Errorf("session = %s, want COMPLETED with pdf %d", stored.Status, pdf.ID)
+	}
+}
+
+func TestChunkedCompleteRejects(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("incomplete", func(t *testing.T) {
+		f := newChunkedFixture(t)
+		sess := f.upload(t, 7, chunkedContent[:10])
+		if _, err := f.svc.Complete(ctx, 7, sess.ID); !errors.Is(err, ErrUploadIncomplete) {
+			t.Errorf("err = %v, want ErrUploadIncomplete", err)
+		}
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		f := newChunkedFixture(t)
+		sess := f.upload(t, 7, chunkedContent)
+		if _, err := f.svc.Complete(ctx, 8, sess.ID); !errors.Is(err, ErrUploadNotFound) {
+			t.Errorf("err = %v, want ErrUploadNotFound", err)
+		}
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		f := newChunkedFixture(t)
+		sess := f.upload(t, 7, strings.Replace(chunkedContent, "test", "TEST", 1))
+		if _, err := f.svc.Complete(ctx, 7, sess.ID); !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("err = %v, want ErrChecksumMismatch", err)
+		}
+		if len(f.pdfs.files) != 0 {
+			t.Error("row created for a file with the wrong checksum")
+		}
+	})
+
+	t.Run("aborted", func(t *testing.T) {
+		f := newChunkedFixture(t)
+		sess := f.upload(t, 7, chunkedContent)
+		if err := f.svc.Abort(ctx, 7, sess.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.svc.Complete(ctx, 7, sess.ID); !errors.Is(err, ErrUploadNotActive) {
+			t.Errorf("err = %v, want ErrUploadNotActive", err)
+		}
+	})
+
+	t.Run("expired while storing", func(t *testing.T) {
+		f := newChunkedFixture(t)
+		sess := f.upload(t, 7, chunkedContent)
+		// Proses lain menandai sesi EXPIRED setelah Complete membaca statusnya
+		f.pdfs.onUpload = func(id string, pdfID int64) (func(), error) {
+			f.uploads.UpdateStatus(ctx, id, model.UploadExpired)
+			return f.uploads.complete(id, pdfID)
+		}
+		if _, err := f.svc.Complete(ctx, 7, sess.ID); !errors.Is(err, ErrUploadNotActive) {
+			t.Errorf("err = %v, want ErrUploadNotActive", err)
+		}
+		if len(f.pdfs.files) != 0 || len(f.storedFiles(t)) != 0 {
+			t.Error("file stored for a session that is no longer active")
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"pdf-management-system/internal/mailer"
+	"pdf-management-system/internal/model"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// VerificationMode controls what an unverified account may do (REQUIRE_EMAIL_VERIFICATION).
+type VerificationMode string
+
+const (
+	VerifyOff      VerificationMode = "off"
+	VerifyForLogin VerificationMode = "login" // unverified users cannot log in
+	VerifyForPdf   VerificationMode = "pdf"   // unverified users can log in but not use /api/pdf
+
+	resendCooldown = time.Minute
+)
+
+var (
+	ErrEmailNotVerified        = errors.New("email address has not been verified")
+	ErrInvalidVerifyToken      = errors.New("invalid or expired verification token")
+	ErrVerificationRateLimited = errors.New("verification email was sent recently, please wait before retrying")
+)
+
+// VerifyEmail marks the account in a valid verification token as verified.
+func (s *AuthService) VerifyEmail(ctx context.Context, tokenString string) (*model.User, error) {
+	token, err := s.Keys.Parse(tokenString, jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidVerifyToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != "email_verify" {
+		return nil, ErrInvalidVerifyToken
+	}
+	userID, ok1 := claims["user_id"].(float64)
+	email, ok2 := claims["email"].(string)
+	if !ok1 || !ok2 {
+		return nil, ErrInvalidVerifyToken
+	}
+
+	user, err := s.Repo.FindUserByID(ctx, int64(userID))
+	if err != nil {
+		return nil, ErrInvalidVerifyToken
+	}
+	// Token hanya berlaku untuk email saat token dibuat
+	if !strings.EqualFold(user.Email, email) {
+		return nil, ErrInvalidVerifyToken
+	}
+
+	if !user.IsEmailVerified {
+		if err := s.Repo.MarkEmailVerified(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		user.IsEmailVerified = true
+	}
+	return user, nil
+}
+
+// ResendVerification sends a new verification email. Unknown or already
+// verified addresses are silently ignored so accounts cannot be enumerated.
+func (s *AuthService) ResendVerification(ctx context.Context, email string) error {
+	if !s.allowMail("verify", email) {
+		return ErrVerificationRateLimited
+	}
+
+	user, err := s.Repo.FindUserByEmail(ctx, email)
+	if err != nil || user.IsEmailVerified {
+		return nil
+	}
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// allowMail limits emails of one kind to one per resendCooldown per address.
+func (s *AuthService) allowMail(kind, email string) bool {
+	key := kind + ":" + strings.ToLower(strings.TrimSpace(email))
+
+	s.resendMu.Lock()
+	defer s.resendMu.Unlock()
+
+	if last, ok := s.lastResend[key]; ok && time.Since(last) < resendCooldown {
+		return false
+	}
+	for k, t := range s.lastResend {
+		if time.Since(t) >= resendCooldown {
+			delete(s.lastResend, k)
+		}
+	}
+	s.lastResend[key] = time.Now()
+	return true
+}
+
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	if s.Mailer == nil {
+		return nil
+	}
+
+	token, err := s.verificationToken(user)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/api/auth/verify?token=%s", strings.TrimRight(s.BaseURL, "/"), url.QueryEscape(token))
+	body := fmt.Sprintf("Halo %s,\n\nSilakan verifikasi alamat email Anda dengan membuka link berikut:\n%s\n\nLink berlaku sampai %s.\n",
+		user.Name, link, time.Now().Add(s.VerificationTTL).Format("02 January 2006 15:04"))
+
+	// Tidak dibatalkan saat client memutus koneksi, request ID tetap terbawa
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	err = s.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi Email - PDF Management System",
+		Body:    body,
+	})
+	if err == nil {
+		slog.InfoContext(ctx, "verification email sent", "target_user_id", user.ID)
+	}
+	return err
+}
+
+func (s *AuthService) verificationToken(user *model.User) (string, error) {
+	claims := jwt.MapClaims{
+		"typ":     "email_verify",
+		"user_id": user.ID,
+		"email":   user.Email,
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(s.VerificationTTL).Unix(),
+	}
+	return s.Keys.Sign(claims)
+}
@@ -0,0 +1,629 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/scanner"
+	"strings"
+	"sync"
+	"time"
+)
+
+// In-memory implementations of the store interfaces for service tests.
+
+type fakePdfStore struct {
+	mu    sync.Mutex
+	files map[int64]*model.PdfFile
+	// onUpload runs inside CreateFromUpload after the insert, see fakeUploadStore.complete.
+	// undo reverts it when the transaction does not commit.
+	onUpload func(sessionID string, pdfID int64) (undo func(), err error)
+	// failCommit, if set, fails the next create after the file was published
+	failCommit error
+	// failScanUpdate, if set, fails every UpdateScanResult
+	failScanUpdate error
+}
+
+func newFakePdfStore() *fakePdfStore {
+	return &fakePdfStore{files: make(map[int64]*model.PdfFile)}
+}
+
+func (f *fakePdfStore) CreateWithFile(ctx context.Context, pdf *model.PdfFile, publish func() error) error {
+	return f.create(pdf, publish, "")
+}
+
+func (f *fakePdfStore) CreateFromUpload(ctx context.Context, pdf *model.PdfFile, sessionID string, publish func() error) error {
+	return f.create(pdf, publish, sessionID)
+}
+
+// create follows the transaction of PdfRepository.createWithFile: insert,
+// session update, publish, commit. Nothing is kept unless every step succeeds.
+func (f *fakePdfStore) create(pdf *model.PdfFile, publish func() error, sessionID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := int64(len(f.files) + 1)
+
+	undo := func() {}
+	if sessionID != "" && f.onUpload != nil {
+		var err error
+		if undo, err = f.onUpload(sessionID, id); err != nil {
+			return err
+		}
+	}
+	if err := publish(); err != nil {
+		undo()
+		return err
+	}
+	if err := f.failCommit; err != nil {
+		f.failCommit = nil
+		undo()
+		return err
+	}
+
+	pdf.ID = id
+	pdf.CreatedAt = time.Now()
+	stored := *pdf
+	f.files[id] = &stored
+	return nil
+}
+
+func (f *fakePdfStore) FindByID(ctx context.Context, id int64) (*model.PdfFile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pdf, ok := f.files[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *pdf
+	return &copied, nil
+}
+
+func (f *fakePdfStore) FindByFilename(ctx context.Context, filename string) (*model.PdfFile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, pdf := range f.files {
+		if pdf.Filename == filename {
+			copied := *pdf
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakePdfStore) FindAll(ctx context.Context, status string, page, limit int) ([]model.PdfFile, int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var files []model.PdfFile
+	for id := int64(1); id <= int64(len(f.files)); id++ {
+		if pdf, ok := f.files[id]; ok && (status == "" || string(pdf.Status) == status) {
+			files = append(files, *pdf)
+		}
+	}
+	return files, int64(len(files)), nil
+}
+
+func (f *fakePdfStore) SoftDelete(ctx context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pdf, ok := f.files[id]
+	if !ok {
+		return fmt.Errorf("file not found")
+	}
+	pdf.Status = model.StatusDeleted
+	return nil
+}
+
+func (f *fakePdfStore) UpdateScanResult(ctx context.Context, id int64, status model.PdfStatus, result string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failScanUpdate != nil {
+		return f.failScanUpdate
+	}
+	if pdf, ok := f.files[id]; ok {
+		pdf.Status = status
+		pdf.ScanResult = &result
+	}
+	return nil
+}
+
+func (f *fakePdfStore) FindPendingScan(ctx context.Context, afterID int64, limit int) ([]model.PdfFile, error) {
+	files, _, _ := f.FindAll(ctx, string(model.StatusPendingScan), 1, 0)
+	var page []model.PdfFile
+	for _, pdf := range files {
+		if pdf.ID > afterID && len(page) < limit {
+			page = append(page, pdf)
+		}
+	}
+	return page, nil
+}
+
+func (f *fakePdfStore) Each(ctx context.Context, fn func(*model.PdfFile) error) error {
+	files, _, _ := f.FindAll(ctx, "", 1, 0)
+	for i := range files {
+		if err := fn(&files[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fakePdfStore) MarkMissing(ctx context.Context, id int64) error {
+	return f.SoftDelete(ctx, id)
+}
+
+// put stores pdf as is, for tests that need a row in a given state.
+func (f *fakePdfStore) put(pdf model.PdfFile) *model.PdfFile {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pdf.ID = int64(len(f.files) + 1)
+	f.files[pdf.ID] = &pdf
+	return &pdf
+}
+
+// stubScanner returns a fixed verdict without reading the file.
+type stubScanner struct {
+	result scanner.Result
+	err    error
+}
+
+func (s stubScanner) Name() string { return "stub" }
+
+func (s stubScanner) Scan(ctx context.Context, r io.Reader) (scanner.Result, error) {
+	return s.result, s.err
+}
+
+// testConfig returns the default config with storage under t.TempDir().
+func testConfig(dir string) *config.Config {
+	cfg := config.Default()
+	cfg.Storage.PdfDir = dir + "/pdf"
+	cfg.Storage.QuarantineDir = dir + "/quarantine"
+	cfg.Storage.UploadTmpDir = dir + "/tmp"
+	return cfg
+}
+
+type fakeUserStore struct {
+	mu    sync.Mutex
+	users map[int64]*model.User
+	roles []model.Role
+}
+
+func newFakeUserStore() *fakeUserStore {
+	return &fakeUserStore{
+		users: make(map[int64]*model.User),
+		roles: []model.Role{{ID: 1, Role: "Admin"}, {ID: 2, Role: "Staff"}, {ID: 3, Role: "Viewer"}},
+	}
+}
+
+func (f *fakeUserStore) CreateUser(ctx context.Context, user *model.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range f.users {
+		if strings.EqualFold(u.Email, user.Email) {
+			return fmt.Errorf("duplicate email %s", user.Email)
+		}
+	}
+	user.ID = int64(len(f.users) + 1)
+	user.CreatedDate = time.Now()
+	for _, r := range f.roles {
+		if r.ID == user.RoleID {
+			user.RoleName = r.Role
+		}
+	}
+	stored := *user
+	f.users[user.ID] = &stored
+	return nil
+}
+
+func (f *fakeUserStore) find(match func(*model.User) bool) (*model.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range f.users {
+		if match(u) {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeUserStore) FindUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	return f.find(func(u *model.User) bool { return strings.EqualFold(u.Email, email) })
+}
+
+func (f *fakeUserStore) FindUserByID(ctx context.Context, id int64) (*model.User, error) {
+	return f.find(func(u *model.User) bool { return u.ID == id })
+}
+
+func (f *fakeUserStore) FindUserByOIDC(ctx context.Context, issuer, subject string) (*model.User, error) {
+	return f.find(func(u *model.User) bool {
+		return u.OIDCIssuer != nil && *u.OIDCIssuer == issuer && u.OIDCSubject != nil && *u.OIDCSubject == subject
+	})
+}
+
+func (f *fakeUserStore) update(id int64, fn func(*model.User)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, ok := f.users[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	fn(u)
+	return nil
+}
+
+func (f *fakeUserStore) LinkOIDC(ctx context.Context, id int64, issuer, subject string) error {
+	return f.update(id, func(u *model.User) { u.OIDCIssuer, u.OIDCSubject = &issuer, &subject })
+}
+
+func (f *fakeUserStore) FindAllUsers(ctx context.Context, active *bool, page, limit int) ([]model.User, int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var users []model.User
+	for _, u := range f.users {
+		if active == nil || u.IsActive == *active {
+			users = append(users, *u)
+		}
+	}
+	return users, int64(len(users)), nil
+}
+
+func (f *fakeUserStore) UpdateUser(ctx context.Context, user *model.User, modifiedBy int64) error {
+	return f.update(user.ID, func(u *model.User) {
+		password := u.Password
+		*u = *user
+		u.Password = password
+		u.ModifiedBy = &modifiedBy
+	})
+}
+
+func (f *fakeUserStore) MarkEmailVerified(ctx context.Context, id int64) error {
+	return f.update(id, func(u *model.User) { u.IsEmailVerified = true })
+}
+
+func (f *fakeUserStore) UpdatePassword(ctx context.Context, id int64, hashedPassword string, modifiedBy int64) error {
+	return f.update(id, func(u *model.User) { u.Password = hashedPassword; u.ModifiedBy = &modifiedBy })
+}
+
+func (f *fakeUserStore) RoleExists(ctx context.Context, id int64) (bool, error) {
+	for _, r := range f.roles {
+		if r.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeUserStore) FindRoleByName(ctx context.Context, name string) (*model.Role, error) {
+	for _, r := range f.roles {
+		if r.Role == name {
+			return &r, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeUserStore) FindAllRoles(ctx context.Context) ([]model.Role, error) {
+	return f.roles, nil
+}
+
+func (f *fakeUserStore) CountActiveUsersWithRole(ctx context.Context, roleID int64) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var n int64
+	for _, u := range f.users {
+		if u.IsActive && u.RoleID == roleID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+type fakeResetToken struct {
+	userID    int64
+	expiresAt time.Time
+	used      bool
+}
+
+type fakeTokenStore struct {
+	mu            sync.Mutex
+	refresh       []*model.RefreshToken
+	revokedJTIs   map[string]bool
+	resets        map[string]*fakeResetToken
+	revokedForAll map[int64]time.Time
+}
+
+func newFakeTokenStore() *fakeTokenStore {
+	return &fakeTokenStore{
+		revokedJTIs:   make(map[string]bool),
+		resets:        make(map[string]*fakeResetToken),
+		revokedForAll: make(map[int64]time.Time),
+	}
+}
+
+func (f *fakeTokenStore) CreateRefreshToken(ctx context.Context, t *model.RefreshToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t.ID = int64(len(f.refresh) + 1)
+	stored := *t
+	f.refresh = append(f.refresh, &stored)
+	return nil
+}
+
+func (f *fakeTokenStore) FindRefreshTokenByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, t := range f.refresh {
+		if t.TokenHash == hash {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeTokenStore) RotateRefreshToken(ctx context.Context, oldID int64, next *model.RefreshToken) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	old := f.refresh[oldID-1]
+	if old.RevokedAt != nil {
+		return false, nil
+	}
+	next.ID = int64(len(f.refresh) + 1)
+	stored := *next
+	f.refresh = append(f.refresh, &stored)
+	now := time.Now()
+	old.RevokedAt, old.ReplacedBy = &now, &next.ID
+	return true, nil
+}
+
+func (f *fakeTokenStore) revokeWhere(match func(*model.RefreshToken) bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	for _, t := range f.refresh {
+		if t.RevokedAt == nil && match(t) {
+			t.RevokedAt = &now
+		}
+	}
+}
+
+func (f *fakeTokenStore) RevokeFamily(ctx context.Context, familyID string) error {
+	f.revokeWhere(func(t *model.RefreshToken) bool { return t.FamilyID == familyID })
+	return nil
+}
+
+func (f *fakeTokenStore) RevokeAllForUser(ctx context.Context, userID int64) error {
+	f.revokeWhere(func(t *model.RefreshToken) bool { return t.UserID == userID })
+	f.mu.Lock()
+	f.revokedForAll[userID] = time.Now()
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeTokenStore) RevokeJTI(ctx context.Context, jti string, userID int64, expiresAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.revokedJTIs[jti] = true
+	return nil
+}
+
+func (f *fakeTokenStore) IsAccessTokenRevoked(ctx context.Context, jti string, userID int64, issuedAt time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	at, ok := f.revokedForAll[userID]
+	return f.revokedJTIs[jti] || (ok && at.Truncate(time.Second).After(issuedAt)), nil
+}
+
+func (f *fakeTokenStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeTokenStore) CreatePasswordReset(ctx context.Context, userID int64, hash string, expiresAt time.Time, ip string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resets[hash] = &fakeResetToken{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+func (f *fakeTokenStore) FindPasswordReset(ctx context.Context, hash string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.resets[hash]
+	if !ok || t.used || !time.Now().Before(t.expiresAt) {
+		return 0, sql.ErrNoRows
+	}
+	return t.userID, nil
+}
+
+func (f *fakeTokenStore) ConsumePasswordReset(ctx context.Context, hash string) (int64, error) {
+	userID, err := f.FindPasswordReset(ctx, hash)
+	if err != nil {
+		return 0, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, t := range f.resets {
+		if t.userID == userID {
+			t.used = true
+		}
+	}
+	return userID, nil
+}
+
+type fakeShareStore struct {
+	mu       sync.Mutex
+	links    []*model.ShareLink
+	accesses []model.ShareLinkAccess
+}
+
+func (f *fakeShareStore) Create(ctx context.Context, l *model.ShareLink) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	l.ID = int64(len(f.links) + 1)
+	stored := *l
+	f.links = append(f.links, &stored)
+	return nil
+}
+
+func (f *fakeShareStore) FindByHash(ctx context.Context, hash string) (*model.ShareLink, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, l := range f.links {
+		if l.TokenHash == hash {
+			copied := *l
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeShareStore) FindActiveByPdf(ctx context.Context, pdfID int64, now time.Time) ([]model.ShareLink, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var links []model.ShareLink
+	for _, l := range f.links {
+		if l.PdfFileID == pdfID && l.RevokedAt == nil && now.Before(l.ExpiresAt) {
+			links = append(links, *l)
+		}
+	}
+	return links, nil
+}
+
+func (f *fakeShareStore) Revoke(ctx context.Context, id, pdfID int64) (*model.ShareLink, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, l := range f.links {
+		if l.ID == id && l.PdfFileID == pdfID && l.RevokedAt == nil {
+			now := time.Now()
+			l.RevokedAt = &now
+			copied := *l
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeShareStore) ClaimDownload(ctx context.Context, id int64, now time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	l := f.links[id-1]
+	if l.RevokedAt != nil || !now.Before(l.ExpiresAt) || (l.MaxDownloads != nil && l.DownloadCount >= *l.MaxDownloads) {
+		return false, nil
+	}
+	l.DownloadCount++
+	l.LastAccessedAt = &now
+	return true, nil
+}
+
+func (f *fakeShareStore) RecordFailedPassword(ctx context.Context, id int64, maxFailures int, now time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	l := f.links[id-1]
+	l.FailedAttempts++
+	l.LastAccessedAt = &now
+	if l.FailedAttempts >= maxFailures && l.RevokedAt == nil {
+		l.RevokedAt = &now
+	}
+	return l.RevokedAt != nil, nil
+}
+
+func (f *fakeShareStore) RecordAccess(ctx context.Context, a *model.ShareLinkAccess) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a.ID = int64(len(f.accesses) + 1)
+	f.accesses = append(f.accesses, *a)
+	return nil
+}
+
+func (f *fakeShareStore) FindAccesses(ctx context.Context, id, pdfID int64, limit int) ([]model.ShareLinkAccess, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var accesses []model.ShareLinkAccess
+	for i := len(f.accesses) - 1; i >= 0 && len(accesses) < limit; i-- {
+		if f.accesses[i].ShareLinkID == id {
+			accesses = append(accesses, f.accesses[i])
+		}
+	}
+	return accesses, nil
+}
+
+type fakeUploadStore struct {
+	mu       sync.Mutex
+	sessions map[string]*model.UploadSession
+}
+
+func newFakeUploadStore() *fakeUploadStore {
+	return &fakeUploadStore{sessions: make(map[string]*model.UploadSession)}
+}
+
+func (f *fakeUploadStore) Create(ctx context.Context, sess *model.UploadSession) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored := *sess
+	f.sessions[sess.ID] = &stored
+	return nil
+}
+
+func (f *fakeUploadStore) FindByID(ctx context.Context, id string) (*model.UploadSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sess, ok := f.sessions[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *sess
+	return &copied, nil
+}
+
+func (f *fakeUploadStore) AdvanceOffset(ctx context.Context, id string, from, to int64, expiresAt time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sess, ok := f.sessions[id]
+	if !ok || sess.Status != model.UploadActive || sess.Offset != from {
+		return false, nil
+	}
+	sess.Offset, sess.ExpiresAt = to, expiresAt
+	return true, nil
+}
+
+func (f *fakeUploadStore) UpdateStatus(ctx context.Context, id string, status model.UploadSessionStatus) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if sess, ok := f.sessions[id]; ok && sess.Status == model.UploadActive {
+		sess.Status = status
+	}
+	return nil
+}
+
+func (f *fakeUploadStore) FindExpired(ctx context.Context, now time.Time) ([]model.UploadSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var expired []model.UploadSession
+	for _, sess := range f.sessions {
+		if sess.Status == model.UploadActive && now.After(sess.ExpiresAt) {
+			expired = append(expired, *sess)
+		}
+	}
+	return expired, nil
+}
+
+// complete is the session update of PdfRepository.CreateFromUpload, used as
+// fakePdfStore.onUpload.
+func (f *fakeUploadStore) complete(sessionID string, pdfID int64) (func(), error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sess, ok := f.sessions[sessionID]
+	if !ok || sess.Status != model.UploadActive {
+		return nil, sql.ErrNoRows
+	}
+	before := *sess
+	sess.Status, sess.PdfFileID = model.UploadCompleted, &pdfID
+	return func() {
+		f.mu.Lock()
+		*f.sessions[sessionID] = before
+		f.mu.Unlock()
+	}, nil
+}
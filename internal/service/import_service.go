@@ -0,0 +1,242 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/model"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const importMaxRedirects = 5
+
+var (
+	ErrInvalidImportURL = errors.New("url must be an absolute http or https URL")
+	ErrImportBlocked    = errors.New("url points to a disallowed address")
+	ErrImportTooLarge   = errors.New("remote file exceeds maximum import size")
+	ErrImportFetch      = errors.New("failed to fetch remote file")
+)
+
+// Rentang alamat non-publik yang tidak dicakup netip (IsPrivate, IsLoopback, dll)
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+type ImportService struct {
+	Pdf          *PdfService
+	Client       *http.Client
+	MaxSize      int64
+	AllowedPorts map[string]bool
+	AllowPrivate bool
+}
+
+func NewImportService(pdf *PdfService, cfg *config.Config) *ImportService {
+	s := &ImportService{
+		Pdf:          pdf,
+		MaxSize:      cfg.Upload.ImportMaxSize,
+		AllowedPorts: map[string]bool{},
+		// Hanya untuk development lokal (misal import dari server di localhost)
+		AllowPrivate: cfg.Upload.ImportAllowPrivate,
+	}
+	for _, p := range cfg.Upload.ImportAllowedPorts {
+		s.AllowedPorts[p] = true
+	}
+
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		// Control dipanggil setelah DNS resolve, jadi DNS rebinding tidak bisa lolos
+		Control: s.checkDialAddress,
+	}
+
+	s.Client = &http.Client{
+		Timeout: 60 * time.Second,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 15 * time.Second,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= importMaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", importMaxRedirects)
+			}
+			return s.checkURL(req.URL)
+		},
+	}
+
+	return s
+}
+
+// Import downloads a PDF from rawURL and stores it as an UPLOADED record with provenance.
+func (s *ImportService) Import(ctx context.Context, rawURL string) (*model.PdfFile, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, ErrInvalidImportURL
+	}
+	if err := s.checkURL(u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, ErrInvalidImportURL
+	}
+	req.Header.Set("Accept", "application/pdf")
+
+	fetchedAt := time.Now()
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrImportBlocked) || errors.Is(err, ErrInvalidImportURL) {
+			return nil, ErrImportBlocked
+		}
+		return nil, fmt.Errorf("%w: %v", ErrImportFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: remote server returned %s", ErrImportFetch, resp.Status)
+	}
+	if resp.ContentLength > s.MaxSize {
+		return nil, ErrImportTooLarge
+	}
+
+	tmp, err := os.CreateTemp("", "import-*.pdf")
+	if err != nil {
+		return nil, err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, s.MaxSize+1))
+	closeErr := tmp.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImportFetch, err)
+	}
+	if closeErr != nil {
+		return nil, closeErr
+	}
+	if n > s.MaxSize {
+		return nil, ErrImportTooLarge
+	}
+
+	if err := checkPdfMagic(tmpPath); err != nil {
+		return nil, err
+	}
+
+	// URL akhir setelah redirect dicatat sebagai sumber
+	sourceURL := resp.Request.URL.String()
+	originalName := importFileName(resp)
+
+	return s.Pdf.StoreFile(ctx, tmpPath, &model.PdfFile{
+		OriginalName: &originalName,
+		SourceURL:    &sourceURL,
+		FetchedAt:    &fetchedAt,
+	})
+}
+
+func (s *ImportService) checkURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return ErrInvalidImportURL
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	if !s.AllowedPorts[port] {
+		return ErrImportBlocked
+	}
+	return nil
+}
+
+func (s *ImportService) checkDialAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return ErrImportBlocked
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return ErrImportBlocked
+	}
+	if s.AllowPrivate {
+		return nil
+	}
+	if !isPublicAddr(addr) {
+		return ErrImportBlocked
+	}
+	return nil
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkPdfMagic(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	magic := make([]byte, 5)
+	if _, err := io.ReadFull(f, magic); err != nil || !bytes.Equal(magic, []byte("%PDF-")) {
+		return ErrNotPdfContent
+	}
+	return nil
+}
+
+// importFileName prefers Content-Disposition, then the last URL path segment.
+func importFileName(resp *http.Response) string {
+	name := ""
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		name = filepath.Base(params["filename"])
+	}
+	if name == "" || name == "." || name == "/" {
+		name = path.Base(resp.Request.URL.Path)
+	}
+	if name == "" || name == "." || name == "/" {
+		name = "import_" + strconv.FormatInt(time.Now().Unix(), 10)
+	}
+	if strings.ToLower(filepath.Ext(name)) != ".pdf" {
+		name += ".pdf"
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
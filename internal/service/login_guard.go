@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/model"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrLoginLocked = errors.New("too many failed login attempts")
+
+// LoginLockedError is returned while an account or IP is locked out.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrLoginLocked.Error(), e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginLockedError) Is(target error) bool {
+	return target == ErrLoginLocked
+}
+
+// LoginAttemptStore keeps failed-login counters. The in-memory store is
+// per-process; use repository.LoginAttemptRepository when running several instances.
+type LoginAttemptStore interface {
+	Get(ctx context.Context, key string) (*model.LoginThrottle, error)
+	RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+	Purge(ctx context.Context, now time.Time, window time.Duration) error
+}
+
+// LoginAuditor records login attempts for auditing.
+type LoginAuditor interface {
+	RecordAttempt(ctx context.Context, a *model.LoginAttempt) error
+}
+
+// LoginGuard throttles logins per account and per IP. After MaxFailures
+// failures within Window the key is locked for LockoutBase, doubling with
+// every further failure up to LockoutMax.
+type LoginGuard struct {
+	Store LoginAttemptStore
+	Audit LoginAuditor
+
+	MaxAccountFailures int
+	MaxIPFailures      int
+	LockoutBase        time.Duration
+	LockoutMax         time.Duration
+	Window             time.Duration
+}
+
+func NewLoginGuard(store LoginAttemptStore, audit LoginAuditor, cfg *config.Config) *LoginGuard {
+	return &LoginGuard{
+		Store:              store,
+		Audit:              audit,
+		MaxAccountFailures: cfg.LoginGuard.MaxAttempts,
+		MaxIPFailures:      cfg.LoginGuard.MaxAttemptsPerIP,
+		LockoutBase:        cfg.LoginGuard.LockoutBase,
+		LockoutMax:         cfg.LoginGuard.LockoutMax,
+		Window:             cfg.LoginGuard.Window,
+	}
+}
+
+// Check returns a *LoginLockedError if the account or the IP is currently locked.
+func (g *LoginGuard) Check(ctx context.Context, email, ip string) error {
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, key := range g.keys(email, ip) {
+		t, err := g.Store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if t.LockedUntil != nil && t.LockedUntil.After(now) {
+			if d := t.LockedUntil.Sub(now); d > retryAfter {
+				retryAfter = d
+			}
+		}
+	}
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Failure counts a failed login and returns a *LoginLockedError if it caused a lockout.
+func (g *LoginGuard) Failure(ctx context.Context, email string, userID *int64, client model.ClientInfo) error {
+	g.audit(ctx, email, userID, client, model.LoginInvalidCredentials)
+
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, key := range g.keys(email, client.IP) {
+		failures, err := g.Store.RecordFailure(ctx, key, now, g.Window)
+		if err != nil {
+			return err
+		}
+		max := g.MaxAccountFailures
+		if strings.HasPrefix(key, "ip:") {
+			max = g.MaxIPFailures
+		}
+		if failures < max {
+			continue
+		}
+
+		lockFor := g.lockoutFor(failures - max)
+		if err := g.Store.Lock(ctx, key, now.Add(lockFor)); err != nil {
+			return err
+		}
+		slog.WarnContext(ctx, "login locked", "key", key, "failures", failures, "lockout", lockFor.String())
+		if lockFor > retryAfter {
+			retryAfter = lockFor
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Locked records a login attempt that was refused because of a lockout.
+func (g *LoginGuard) Locked(ctx context.Context, email string, client model.ClientInfo) {
+	g.audit(ctx, email, nil, client, model.LoginLocked)
+}
+
+// Success clears the account counter. The IP counter is kept so that logging
+// in to one's own account does not reset a guessing run against others.
+func (g *LoginGuard) Success(ctx context.Context, email string) error {
+	return g.Store.Reset(ctx, accountKey(email))
+}
+
+// Unlock clears the account counter and, if ip is set, the IP counter.
+func (g *LoginGuard) Unlock(ctx context.Context, email, ip string, userID *int64, adminIP string) error {
+	if err := g.Store.Reset(ctx, accountKey(email)); err != nil {
+		return err
+	}
+	if ip != "" {
+		if err := g.Store.Reset(ctx, "ip:"+ip); err != nil {
+			return err
+		}
+	}
+	g.audit(ctx, email, userID, model.ClientInfo{IP: adminIP}, model.LoginUnlocked)
+	return nil
+}
+
+// Purge drops counters that are no longer relevant.
+func (g *LoginGuard) Purge(ctx context.Context) error {
+	return g.Store.Purge(ctx, time.Now(), g.Window)
+}
+
+// lockoutFor returns LockoutBase * 2^n, capped at LockoutMax.
+func (g *LoginGuard) lockoutFor(n int) time.Duration {
+	if n > 30 {
+		return g.LockoutMax
+	}
+	d := time.Duration(float64(g.LockoutBase) * math.Pow(2, float64(n)))
+	if d > g.LockoutMax || d <= 0 {
+		return g.LockoutMax
+	}
+	return d
+}
+
+func (g *LoginGuard) keys(email, ip string) []string {
+	keys := []string{accountKey(email)}
+	if ip != "" {
+		keys = append(keys, "ip:"+ip)
+	}
+	return keys
+}
+
+func (g *LoginGuard) audit(ctx context.Context, email string, userID *int64, client model.ClientInfo, reason string) {
+	if g.Audit == nil {
+		return
+	}
+	attempt := &model.LoginAttempt{
+		Email:       truncateRunes(email, 255),
+		UserID:      userID,
+		IPAddress:   client.IP,
+		UserAgent:   truncateRunes(client.UserAgent, 255),
+		Reason:      reason,
+		AttemptedAt: time.Now(),
+	}
+	if err := g.Audit.RecordAttempt(ctx, attempt); err != nil {
+		slog.ErrorContext(ctx, "failed to record login attempt", "error", err)
+	}
+}
+
+func accountKey(email string) string {
+	return "account:" + truncateRunes(strings.ToLower(strings.TrimSpace(email)), 255)
+}
+
+// MemoryLoginAttemptStore is the default single-instance LoginAttemptStore.
+type MemoryLoginAttemptStore struct {
+	mu      sync.Mutex
+	entries map[string]*model.LoginThrottle
+}
+
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{entries: make(map[string]*model.LoginThrottle)}
+}
+
+func (m *MemoryLoginAttemptStore) Get(_ context.Context, key string) (*model.LoginThrottle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.entries[key]; ok {
+		copied := *t
+		return &copied, nil
+	}
+	return &model.LoginThrottle{Key: key}, nil
+}
+
+func (m *MemoryLoginAttemptStore) RecordFailure(_ context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.entries[key]
+	if !ok || t.LastFailureAt.Before(now.Add(-window)) {
+		t = &model.LoginThrottle{Key: key}
+		m.entries[key] = t
+	}
+	t.Failures++
+	t.LastFailureAt = now
+	return t.Failures, nil
+}
+
+func (m *MemoryLoginAttemptStore) Lock(_ context.Context, key string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.entries[key]; ok {
+		t.LockedUntil = &until
+	}
+	return nil
+}
+
+func (m *MemoryLoginAttemptStore) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
+
+func (m *MemoryLoginAttemptStore) Purge(_ context.Context, now time.Time, window time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, t := range m.entries {
+		if t.LastFailureAt.Before(now.Add(-window)) && (t.LockedUntil == nil || t.LockedUntil.Before(now)) {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/oidc"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrOIDCDisabled      = errors.New("single sign-on is not configured")
+	ErrOIDCState         = errors.New("login state is missing, expired or does not match")
+	ErrOIDCNoEmail       = errors.New("identity provider did not return an email address")
+	ErrOIDCEmailConflict = errors.New("an account with this email already exists and the identity provider has not verified the email")
+)
+
+// OIDCService logs users in through an OpenID Connect provider, creating
+// accounts on first login, and issues our own session tokens.
+type OIDCService struct {
+	Config      oidc.Config
+	Auth        *AuthService
+	GroupsClaim string
+	// Urutan menentukan prioritas jika user ada di beberapa grup
+	RoleMapping       []config.OIDCRoleMapping
+	PostLoginRedirect string
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// NewOIDCService returns nil when cfg.OIDC.Issuer is not set.
+func NewOIDCService(auth *AuthService, cfg *config.Config) *OIDCService {
+	c := cfg.OIDC
+	if c.Issuer == "" {
+		return nil
+	}
+
+	redirect := c.RedirectURL
+	if redirect == "" {
+		redirect = strings.TrimRight(cfg.Server.BaseURL, "/") + "/api/auth/oidc/callback"
+	}
+
+	return &OIDCService{
+		Config: oidc.Config{
+			Issuer:       c.Issuer,
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			RedirectURL:  redirect,
+			Scopes:       c.Scopes,
+		},
+		Auth:              auth,
+		GroupsClaim:       c.GroupsClaim,
+		RoleMapping:       c.RoleMapping,
+		PostLoginRedirect: c.PostLoginRedirect,
+	}
+}
+
+// Begin starts a login. It returns the provider URL to redirect to and the
+// state to keep in a cookie until the callback.
+func (s *OIDCService) Begin(ctx context.Context) (string, string, error) {
+	p, err := s.getProvider(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	var parts [3]string // state, nonce, PKCE verifier
+	for i := range parts {
+		if parts[i], err = randomToken(32); err != nil {
+			return "", "", err
+		}
+	}
+	return p.AuthCodeURL(parts[0], parts[1], parts[2]), strings.Join(parts[:], "."), nil
+}
+
+// Complete handles the callback: checks state, exchanges the code, validates
+// the ID token, provisions the user and issues a session.
+func (s *OIDCService) Complete(ctx context.Context, code, state, cookieState string, client model.ClientInfo) (*model.AuthResponse, error) {
+	parts := strings.Split(cookieState, ".")
+	if len(parts) != 3 || state == "" || parts[0] != state {
+		return nil, ErrOIDCState
+	}
+	nonce, verifier := parts[1], parts[2]
+
+	p, err := s.getProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := p.VerifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.provision(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	familyID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	return s.Auth.issueSession(ctx, user, familyID, client, 0)
+}
+
+// provision finds the user for the ID token, linking by verified email or
+// creating a new account, and syncs the role from the groups claim.
+func (s *OIDCService) provision(ctx context.Context, claims jwt.MapClaims) (*model.User, error) {
+	repo := s.Auth.Repo
+	issuer := s.Config.Issuer
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	email = strings.TrimSpace(email)
+	emailVerified, _ := claims["email_verified"].(bool)
+	roleName := s.mapRole(oidc.StringList(claims, s.GroupsClaim))
+
+	user, err := repo.FindUserByOIDC(ctx, issuer, subject)
+	if err == sql.ErrNoRows {
+		if email == "" {
+			return nil, ErrOIDCNoEmail
+		}
+		existing, err := repo.FindUserByEmail(ctx, email)
+		switch {
+		case err == nil:
+			// Hanya ditautkan jika IdP menjamin email milik user ini
+			if !emailVerified || existing.OIDCSubject != nil {
+				return nil, ErrOIDCEmailConflict
+			}
+			if err := repo.LinkOIDC(ctx, existing.ID, issuer, subject); err != nil {
+				return nil, err
+			}
+			slog.InfoContext(ctx, "linked user to SSO subject", "target_user_id", existing.ID, "subject", subject)
+			user = existing
+		case err == sql.ErrNoRows:
+			if user, err = s.createUser(ctx, claims, email, emailVerified, subject, roleName); err != nil {
+				return nil, err
+			}
+			slog.InfoContext(ctx, "provisioned SSO user", "target_user_id", user.ID, "email", email)
+			return user, nil
+		default:
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+
+	// Grup di IdP menentukan role; tanpa grup yang cocok role tidak diubah
+	if roleName != "" {
+		role, err := repo.FindRoleByName(ctx, roleName)
+		if err != nil {
+			slog.WarnContext(ctx, "OIDC role mapping points to unknown role", "role", roleName, "error", err)
+		} else if role.ID != user.RoleID {
+			last, err := s.isLastAdmin(ctx, user)
+			if err != nil {
+				return nil, err
+			}
+			if last {
+				// Sama seperti UserService.checkAdminRemains: admin aktif terakhir tidak diturunkan
+				slog.WarnContext(ctx, "OIDC role mapping not applied", "target_user_id", user.ID, "role", roleName, "error", ErrLastAdmin)
+				return repo.FindUserByID(ctx, user.ID)
+			}
+			user.RoleID = role.ID
+			if err := repo.UpdateUser(ctx, user, user.ID); err != nil {
+				return nil, err
+			}
+			if err := s.Auth.Tokens.RevokeAllForUser(ctx, user.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return repo.FindUserByID(ctx, user.ID)
+}
+
+// isLastAdmin reports whether user is the only active admin left.
+func (s *OIDCService) isLastAdmin(ctx context.Context, user *model.User) (bool, error) {
+	adminRole, err := s.Auth.Repo.FindRoleByName(ctx, AdminRoleName)
+	if err != nil {
+		return false, err
+	}
+	if !user.IsActive || user.RoleID != adminRole.ID {
+		return false, nil
+	}
+	n, err := s.Auth.Repo.CountActiveUsersWithRole(ctx, adminRole.ID)
+	if err != nil {
+		return false, err
+	}
+	return n <= 1, nil
+}
+
+func (s *OIDCService) createUser(ctx context.Context, claims jwt.MapClaims, email string, emailVerified bool, subject, roleName string) (*model.User, error) {
+	repo := s.Auth.Repo
+	if len(email) > 255 {
+		return nil, errors.New("email from identity provider is too long")
+	}
+	if roleName == "" {
+		roleName = s.Auth.DefaultRole
+	}
+	role, err := repo.FindRoleByName(ctx, roleName)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load role for SSO user", "role", roleName, "error", err)
+		return nil, ErrDefaultRoleNotFound
+	}
+
+	issuer := s.Config.Issuer
+	user := &model.User{
+		Name:            claimName(claims, email),
+		Email:           email,
+		RoleID:          role.ID,
+		IsEmailVerified: emailVerified,
+		IsActive:        true,
+		OIDCIssuer:      &issuer,
+		OIDCSubject:     &subject,
+		CreatedDate:     time.Now(),
+	}
+	if err := repo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return repo.FindUserByID(ctx, user.ID)
+}
+
+func (s *OIDCService) mapRole(groups []string) string {
+	for _, m := range s.RoleMapping {
+		for _, g := range groups {
+			if g == m.Group {
+				return m.Role
+			}
+		}
+	}
+	return ""
+}
+
+// getProvider runs discovery on first use so the server can start while the IdP is down.
+func (s *OIDCService) getProvider(ctx context.Context) (*oidc.Provider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider != nil {
+		return s.provider, nil
+	}
+	p, err := oidc.Discover(ctx, s.Config, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.provider = p
+	return p, nil
+}
+
+func claimName(claims jwt.MapClaims, email string) string {
+	name, _ := claims["name"].(string)
+	if name == "" {
+		given, _ := claims["given_name"].(string)
+		family, _ := claims["family_name"].(string)
+		name = strings.TrimSpace(given + " " + family)
+	}
+	if name == "" {
+		name, _ = claims["preferred_username"].(string)
+	}
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	// Kolom name VARCHAR(50)
+	return truncateRunes(name, 50)
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/oidc"
+	"pdf-management-system/internal/oidc/oidctest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const oidcRedirect = "http://app.test/api/auth/oidc/callback"
+
+type oidcFixture struct {
+	svc   *OIDCService
+	idp   *oidctest.Provider
+	users *fakeUserStore
+}
+
+func newOIDCFixture(t *testing.T) *oidcFixture {
+	t.Helper()
+	idp, err := oidctest.New("pdfms", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(idp)
+	t.Cleanup(srv.Close)
+	idp.Issuer = srv.URL
+	idp.Groups = []string{"pdf-staff"}
+
+	auth := newAuthService(t)
+	auth.DefaultRole = "Viewer"
+
+	return &oidcFixture{
+		svc: &OIDCService{
+			Config: oidc.Config{
+				Issuer:       srv.URL,
+				ClientID:     "pdfms",
+				ClientSecret: "secret",
+				RedirectURL:  oidcRedirect,
+				Scopes:       []string{"openid", "email", "profile"},
+			},
+			Auth:        auth,
+			GroupsClaim: "groups",
+			RoleMapping: []config.OIDCRoleMapping{{Group: "pdf-admins", Role: "Admin"}, {Group: "pdf-staff", Role: "Staff"}},
+		},
+		idp:   idp,
+		users: auth.Repo.(*fakeUserStore),
+	}
+}
+
+// addAdmin adds another active admin, so the SSO user is not the last one.
+func (f *oidcFixture) addAdmin(t *testing.T) {
+	t.Helper()
+	admin := &model.User{Name: "Other", Email: "other-admin@example.com", RoleID: 1, IsActive: true}
+	if err := f.users.CreateUser(context.Background(), admin); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// authorize runs Begin and follows the redirect to the IdP, which approves
+// the login right away. It returns the code and state of the callback and
+// the cookie state.
+func (f *oidcFixture) authorize(t *testing.T) (code, state, cookieState string) {
+	t.Helper()
+	authURL, cookieState, err := f.svc.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || !strings.HasPrefix(callback.String(), oidcRedirect) {
+		t.Fatalf("IdP redirected to %q (%v), want the callback", resp.Header.Get("Location"), err)
+	}
+	return callback.Query().Get("code"), callback.Query().Get("state"), cookieState
+}
+
+func (f *oidcFixture) login(t *testing.T) (*model.AuthResponse, error) {
+	t.Helper()
+	code, state, cookieState := f.authorize(t)
+	return f.svc.Complete(context.Background(), code, state, cookieState, model.ClientInfo{})
+}
+
+func TestOIDCLoginRoundTrip(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+
+	authURL, cookieState, err := f.svc.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(cookieState, ".")
+	q, _ := url.Parse(authURL)
+	if len(parts) != 3 || q.Query().Get("state") != parts[0] || q.Query().Get("nonce") != parts[1] ||
+		q.Query().Get("code_challenge_method") != "S256" || strings.Contains(authURL, parts[2]) {
+		t.Fatalf("authorization URL %s does not match cookie state (the PKCE verifier must stay private)", authURL)
+	}
+
+	resp, err := f.login(t)
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if resp.User.Email != "sso.user@example.com" || resp.User.Name != "SSO User" || !resp.User.IsEmailVerified {
+		t.Errorf("provisioned user = %+v", resp.User)
+	}
+	if resp.User.OIDCIssuer == nil || *resp.User.OIDCIssuer != f.svc.Config.Issuer || resp.User.OIDCSubject == nil {
+		t.Errorf("user not bound to the IdP subject: %+v", resp.User)
+	}
+	if _, err := f.svc.Auth.ValidateAccessToken(ctx, resp.Token); err != nil {
+		t.Errorf("issued access token rejected: %v", err)
+	}
+
+	again, err := f.login(t)
+	if err != nil || again.User.ID != resp.User.ID || len(f.users.users) != 1 {
+		t.Errorf("second login = user %v, %v; want the same user and no new account", again, err)
+	}
+}
+
+func TestOIDCRejectsCallback(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("state mismatch", func(t *testing.T) {
+		f := newOIDCFixture(t)
+		code, _, cookieState := f.authorize(t)
+		if _, err := f.svc.Complete(ctx, code, "forged", cookieState, model.ClientInfo{}); !errors.Is(err, ErrOIDCState) {
+			t.Errorf("err = %v, want ErrOIDCState", err)
+		}
+	})
+
+	t.Run("missing cookie", func(t *testing.T) {
+		f := newOIDCFixture(t)
+		code, state, _ := f.authorize(t)
+		if _, err := f.svc.Complete(ctx, code, state, "", model.ClientInfo{}); !errors.Is(err, ErrOIDCState) {
+			t.Errorf("err = %v, want ErrOIDCState", err)
+		}
+	})
+
+	t.Run("wrong PKCE verifier", func(t *testing.T) {
+		f := newOIDCFixture(t)
+		code, state, cookieState := f.authorize(t)
+		parts := strings.Split(cookieState, ".")
+		parts[2] = "stolen-code-without-verifier"
+		_, err := f.svc.Complete(ctx, code, state, strings.Join(parts, "."), model.ClientInfo{})
+		if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+			t.Errorf("err = %v, want invalid_grant from the IdP", err)
+		}
+	})
+
+	t.Run("code used twice", func(t *testing.T) {
+		f := newOIDCFixture(t)
+		code, state, cookieState := f.authorize(t)
+		if _, err := f.svc.Complete(ctx, code, state, cookieState, model.ClientInfo{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.svc.Complete(ctx, code, state, cookieState, model.ClientInfo{}); err == nil {
+			t.Error("code accepted twice")
+		}
+	})
+}
+
+func TestOIDCRejectsIDToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		method jwt.SigningMethod
+	}{
+		{name: "wrong audience", claims: map[string]interface{}{"aud": "other-client"}},
+		{name: "wrong nonce", claims: map[string]interface{}{"nonce": "replayed"}},
+		{name: "missing nonce", claims: map[string]interface{}{"nonce": nil}},
+		{name: "wrong issuer", claims: map[string]interface{}{"iss": "https://evil.example"}},
+		{name: "expired", claims: map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}},
+		{name: "missing subject", claims: map[string]interface{}{"sub": nil}},
+		{name: "alg HS256 with client secret", method: jwt.SigningMethodHS256},
+		{name: "alg none", method: jwt.SigningMethodNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOIDCFixture(t)
+			f.idp.Configure(func(p *oidctest.Provider) {
+				p.Claims = tt.claims
+				p.SigningMethod = tt.method
+			})
+
+			if _, err := f.login(t); !errors.Is(err, oidc.ErrInvalidIDToken) {
+				t.Errorf("err = %v, want ErrInvalidIDToken", err)
+			}
+			if len(f.users.users) != 0 {
+				t.Error("user provisioned from a rejected ID token")
+			}
+		})
+	}
+}
+
+func TestOIDCLinksExistingEmailOnlyWhenVerified(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+	local := &model.User{Name: "Local", Email: "SSO.User@example.com", Password: "hash", RoleID: 2, IsActive: true}
+	f.users.CreateUser(ctx, local)
+
+	f.idp.Configure(func(p *oidctest.Provider) { p.EmailVerified = false })
+	if _, err := f.login(t); !errors.Is(err, ErrOIDCEmailConflict) {
+		t.Fatalf("unverified email: err = %v, want ErrOIDCEmailConflict", err)
+	}
+	if u, _ := f.users.FindUserByID(ctx, local.ID); u.OIDCSubject != nil {
+		t.Fatal("account linked on an unverified email")
+	}
+
+	f.idp.Configure(func(p *oidctest.Provider) { p.EmailVerified = true })
+	resp, err := f.login(t)
+	if err != nil {
+		t.Fatalf("verified email: %v", err)
+	}
+	if resp.User.ID != local.ID || resp.User.OIDCSubject == nil || len(f.users.users) != 1 {
+		t.Errorf("login = user %d (subject %v), want the existing user %d linked", resp.User.ID, resp.User.OIDCSubject, local.ID)
+	}
+}
+
+func TestOIDCGroupRoleMapping(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+	roleOf := func(resp *model.AuthResponse) string { return resp.User.RoleName }
+
+	// Urutan mapping menentukan prioritas: admin menang atas staff
+	f.idp.Configure(func(p *oidctest.Provider) { p.Groups = []string{"pdf-staff", "pdf-admins"} })
+	first, err := f.login(t)
+	if err != nil || roleOf(first) != "Admin" {
+		t.Fatalf("first login = role %v, %v; want Admin", first, err)
+	}
+
+	// Grup berubah di IdP: role ikut berubah dan sesi lama dicabut
+	f.addAdmin(t)
+	f.idp.Configure(func(p *oidctest.Provider) { p.Groups = []string{"pdf-staff"} })
+	second, err := f.login(t)
+	if err != nil || second.User.RoleID != 2 {
+		t.Fatalf("second login = %v, %v; want role Staff", second, err)
+	}
+	if _, err := f.svc.Auth.Refresh(ctx, first.RefreshToken, model.ClientInfo{}); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("session from before the role change: err = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	// Tanpa grup yang cocok role tidak diubah
+	f.idp.Configure(func(p *oidctest.Provider) { p.Groups = []string{"marketing"} })
+	third, err := f.login(t)
+	if err != nil || third.User.RoleID != 2 {
+		t.Errorf("login without mapped group = %v, %v; want role unchanged", third, err)
+	}
+
+	// User baru tanpa grup yang cocok mendapat role default
+	g := newOIDCFixture(t)
+	g.idp.Configure(func(p *oidctest.Provider) { p.Groups = nil })
+	resp, err := g.login(t)
+	if err != nil || roleOf(resp) != "Viewer" {
+		t.Errorf("new user without mapped group = %v, %v; want default role Viewer", resp, err)
+	}
+}
+
+func TestOIDCGroupSyncKeepsLastAdmin(t *testing.T) {
+	f := newOIDCFixture(t)
+	f.idp.Configure(func(p *oidctest.Provider) { p.Groups = []string{"pdf-admins"} })
+	if _, err := f.login(t); err != nil {
+		t.Fatal(err)
+	}
+
+	// Satu-satunya admin keluar dari grup admin di IdP: role tetap Admin
+	f.idp.Configure(func(p *oidctest.Provider) { p.Groups = []string{"pdf-staff"} })
+	resp, err := f.login(t)
+	if err != nil || resp.User.RoleID != 1 {
+		t.Fatalf("login of the last admin = %v, %v; want role Admin kept", resp, err)
+	}
+
+	// Setelah ada admin lain, penurunan role diterapkan
+	f.addAdmin(t)
+	resp, err = f.login(t)
+	if err != nil || resp.User.RoleID != 2 {
+		t.Errorf("login with another admin = %v, %v; want role Staff", resp, err)
+	}
+}
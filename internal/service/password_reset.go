@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"pdf-management-system/internal/mailer"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/validation"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidResetToken = errors.New("invalid, used or expired reset token")
+	ErrWrongPassword     = errors.New("current password is incorrect")
+	ErrResetRateLimited  = errors.New("reset email was sent recently, please wait before retrying")
+	ErrPasswordUnchanged = errors.New("new password must differ from the current password")
+)
+
+// ForgotPassword emails a single-use reset token. Unknown addresses are
+// silently ignored so accounts cannot be enumerated.
+func (s *AuthService) ForgotPassword(ctx context.Context, email string, client model.ClientInfo) error {
+	if !s.allowMail("reset", email) {
+		return ErrResetRateLimited
+	}
+
+	user, err := s.Repo.FindUserByEmail(ctx, email)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	// User SSO tidak punya password lokal
+	if user.Password == "" {
+		return nil
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.PasswordResetTTL)
+	if err := s.Tokens.CreatePasswordReset(ctx, user.ID, hashToken(token), expiresAt, client.IP); err != nil {
+		return err
+	}
+
+	instructions := fmt.Sprintf("Kirim token berikut ke POST %s/api/auth/reset-password bersama password baru Anda:\n%s",
+		strings.TrimRight(s.BaseURL, "/"), token)
+	if s.PasswordResetURL != "" {
+		instructions = fmt.Sprintf("Buka link berikut untuk membuat password baru:\n%s?token=%s", s.PasswordResetURL, url.QueryEscape(token))
+	}
+	body := fmt.Sprintf("Halo %s,\n\nKami menerima permintaan reset password untuk akun Anda.\n%s\n\nToken hanya bisa dipakai sekali dan berlaku sampai %s.\nAbaikan email ini jika Anda tidak meminta reset password.\n",
+		user.Name, instructions, expiresAt.Format("02 January 2006 15:04"))
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	if err := s.Mailer.Send(ctx, mailer.Message{To: user.Email, Subject: "Reset Password - PDF Management System", Body: body}); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "password reset email sent", "target_user_id", user.ID)
+	return nil
+}
+
+// ResetPassword consumes a reset token, sets the new password and logs out every session.
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	hash := hashToken(token)
+	userID, err := s.Tokens.FindPasswordReset(ctx, hash)
+	if err == sql.ErrNoRows {
+		return ErrInvalidResetToken
+	} else if err != nil {
+		return err
+	}
+	user, err := s.Repo.FindUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Validasi sebelum token dipakai, supaya password yang ditolak bisa diulang dengan token yang sama
+	var errs validation.Errors
+	validation.Password(&errs, "new_password", newPassword, user.Email)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	userID, err = s.Tokens.ConsumePasswordReset(ctx, hash)
+	if err == sql.ErrNoRows {
+		return ErrInvalidResetToken
+	} else if err != nil {
+		return err
+	}
+
+	return s.setPassword(ctx, userID, newPassword, userID)
+}
+
+// ChangePassword verifies the current password, sets the new one and logs
+// out every other session. A fresh session is returned for the caller.
+func (s *AuthService) ChangePassword(ctx context.Context, userID int64, req model.ChangePasswordRequest, client model.ClientInfo) (*model.AuthResponse, error) {
+	user, err := s.Repo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return nil, ErrWrongPassword
+	}
+	if req.CurrentPassword == req.NewPassword {
+		return nil, ErrPasswordUnchanged
+	}
+	var errs validation.Errors
+	validation.Password(&errs, "new_password", req.NewPassword, user.Email)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if err := s.setPassword(ctx, userID, req.NewPassword, userID); err != nil {
+		return nil, err
+	}
+
+	familyID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueSession(ctx, user, familyID, client, 0)
+}
+
+func (s *AuthService) setPassword(ctx context.Context, userID int64, newPassword string, modifiedBy int64) error {
+	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Repo.UpdatePassword(ctx, userID, string(hashedPwd), modifiedBy); err != nil {
+		return err
+	}
+
+	// Semua sesi lama (refresh & access token) tidak berlaku lagi
+	return s.Tokens.RevokeAllForUser(ctx, userID)
+}
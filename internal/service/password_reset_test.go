@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/validation"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	users, tokens := newFakeUserStore(), newFakeTokenStore()
+	s := &AuthService{Repo: users, Tokens: tokens}
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("old-secret-1"), bcrypt.MinCost)
+	user := &model.User{Email: "ana1234@example.com", Password: string(hashed), RoleID: 2, IsActive: true}
+	if err := users.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	tokens.CreatePasswordReset(ctx, user.ID, hashToken("reset-token"), time.Now().Add(time.Hour), "127.0.0.1")
+
+	// Password sama dengan email ditolak, dan token tetap bisa dipakai
+	err := s.ResetPassword(ctx, "reset-token", "ANA1234@example.com")
+	var errs validation.Errors
+	if !errors.As(err, &errs) || errs[0].Code != validation.CodeWeakPassword {
+		t.Fatalf("err = %v, want a weak password validation error", err)
+	}
+
+	if err := s.ResetPassword(ctx, "reset-token", "new-secret-2"); err != nil {
+		t.Fatalf("ResetPassword after rejected attempt: %v", err)
+	}
+	stored, _ := users.FindUserByID(ctx, user.ID)
+	if bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte("new-secret-2")) != nil {
+		t.Error("password not updated")
+	}
+
+	if err := s.ResetPassword(ctx, "reset-token", "another-secret-3"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("reused token: err = %v, want ErrInvalidResetToken", err)
+	}
+	if err := s.ResetPassword(ctx, "unknown-token", "another-secret-3"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("unknown token: err = %v, want ErrInvalidResetToken", err)
+	}
+}
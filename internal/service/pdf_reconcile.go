@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"pdf-management-system/internal/model"
+	"strings"
+	"time"
+)
+
+// ReconcileGrace skips files modified more recently than this, so uploads
+// that are still being written or committed are not reported as orphans.
+const ReconcileGrace = time.Hour
+
+// Reconcile compares the files in Dir and QuarantineDir with pdf_files. With
+// fix set, orphan and stale temporary files are deleted and rows whose file
+// is gone are marked DELETED; otherwise it only reports.
+func (s *PdfService) Reconcile(ctx context.Context, fix bool) (*model.ReconcileReport, error) {
+	report := &model.ReconcileReport{Fixed: fix}
+	cutoff := time.Now().Add(-ReconcileGrace)
+
+	onDisk := make(map[string]string) // filename -> dir
+	for _, dir := range []string{s.Dir, s.QuarantineDir} {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.Type().IsRegular() {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if strings.HasPrefix(e.Name(), tempPrefix) {
+				if info, err := e.Info(); err == nil && info.ModTime().Before(cutoff) {
+					report.StaleTempFiles = append(report.StaleTempFiles, path)
+				}
+				continue
+			}
+			onDisk[e.Name()] = dir
+		}
+	}
+
+	var missing []model.PdfFile
+	err := s.Repo.Each(ctx, func(pdf *model.PdfFile) error {
+		_, exists := onDisk[pdf.Filename]
+		delete(onDisk, pdf.Filename)
+		if !exists && pdf.Status != model.StatusDeleted {
+			missing = append(missing, *pdf)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.MissingFiles = missing
+
+	for name, dir := range onDisk {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		report.OrphanFiles = append(report.OrphanFiles, path)
+	}
+
+	if !fix {
+		return report, nil
+	}
+
+	for _, path := range append(report.OrphanFiles, report.StaleTempFiles...) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return report, err
+		}
+		slog.InfoContext(ctx, "reconcile: removed file without row", "file", path)
+	}
+	for _, pdf := range report.MissingFiles {
+		if err := s.Repo.MarkMissing(ctx, pdf.ID); err != nil {
+			return report, err
+		}
+		slog.InfoContext(ctx, "reconcile: marked row with missing file as DELETED", "pdf_id", pdf.ID, "file", pdf.Filename)
+	}
+	return report, nil
+}
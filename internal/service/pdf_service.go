@@ -1,28 +1,67 @@
 package service
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/metrics"
 	"pdf-management-system/internal/model"
-	"pdf-management-system/internal/repository"
+	"pdf-management-system/internal/scanner"
+	"pdf-management-system/internal/validation"
 	"time"
 
 	"github.com/jung-kurt/gofpdf"
 )
 
+// Jumlah row PENDING_SCAN yang diambil per query saat rescan
+const rescanBatchSize = 100
+
+var (
+	ErrFileNotClean = errors.New("file has not passed malware scan")
+)
+
 type PdfService struct {
-	Repo *repository.PdfRepository
+	Repo    PdfStore
+	Scanner scanner.Scanner
+
+	// Dir holds served files (URL /uploads/pdf/), QuarantineDir infected ones
+	Dir           string
+	QuarantineDir string
 }
 
-func NewPdfService(repo *repository.PdfRepository) *PdfService {
-	return &PdfService{Repo: repo}
+func NewPdfService(repo PdfStore, sc scanner.Scanner, cfg *config.Config) *PdfService {
+	if sc == nil {
+		sc = scanner.NoopScanner{}
+	}
+	return &PdfService{Repo: repo, Scanner: sc, Dir: cfg.Storage.PdfDir, QuarantineDir: cfg.Storage.QuarantineDir}
+}
+
+func (s *PdfService) GeneratePDF(ctx context.Context, req model.GeneratePdfRequest) (*model.PdfFile, error) {
+	start := time.Now()
+	record, err := s.generatePDF(ctx, req)
+
+	var invalid validation.Errors
+	metrics.PdfGenerated.Inc(metrics.Outcome(err, errors.As(err, &invalid)))
+	if err == nil {
+		metrics.PdfGenerateDuration.Observe(time.Since(start).Seconds())
+	}
+	return record, err
 }
 
-func (s *PdfService) GeneratePDF(req model.GeneratePdfRequest) (*model.PdfFile, error) {
+func (s *PdfService) generatePDF(ctx context.Context, req model.GeneratePdfRequest) (*model.PdfFile, error) {
+	if err := validation.ValidateGeneratePdf(req).Err(); err != nil {
+		return nil, err
+	}
+
 	pdf := gofpdf.New("P", "mm", "A4", "")
 
 	// Setup Footer (Recurring on all pages)
@@ -46,8 +85,19 @@ func (s *PdfService) GeneratePDF(req model.GeneratePdfRequest) (*model.PdfFile,
 	}
 
 	// Fetch logo
-	resp, err := http.Get(logoURL)
-	if err == nil && resp.StatusCode == 200 {
+	var resp *http.Response
+	logoReq, err := http.NewRequestWithContext(ctx, http.MethodGet, logoURL, nil)
+	if err == nil {
+		resp, err = http.DefaultClient.Do(logoReq)
+	}
+	if err == nil && resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		err = fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if err != nil {
+		metrics.LogoFetchFailures.Inc()
+		slog.WarnContext(ctx, "failed to fetch logo, generating without it", "url", logoURL, "error", err)
+	} else {
 		defer resp.Body.Close()
 		imgName := "logo_header"
 		opts := gofpdf.ImageOptions{ImageType: "PNG", ReadDpi: true}
@@ -90,90 +140,355 @@ func (s *PdfService) GeneratePDF(req model.GeneratePdfRequest) (*model.PdfFile,
 	pdf.SetFont("Arial", "", 12)
 	pdf.MultiCell(0, 8, req.Content, "", "L", false)
 
-	// Save file
+	// Save file: ditulis ke file sementara, baru dipindah saat row DB di-commit
 	filename := fmt.Sprintf("report_%s_%d.pdf", time.Now().Format("20060102"), time.Now().UnixNano())
-	outputPath := filepath.Join("uploads", "pdf", filename)
-
-	err = pdf.OutputFileAndClose(outputPath)
+	tmpPath, size, err := s.writeTemp(pdf.Output)
 	if err != nil {
 		return nil, fmt.Errorf("failed to save pdf: %v", err)
 	}
 
-	// Save to DB
-	dbFilepath := fmt.Sprintf("/uploads/pdf/%s", filename)
 	pdfRecord := &model.PdfFile{
 		Filename:  filename,
-		Filepath:  dbFilepath,
+		Filepath:  fmt.Sprintf("/uploads/pdf/%s", filename),
+		Size:      size,
 		Status:    model.StatusCreated,
 		CreatedAt: time.Now(),
 	}
-
-	// Get file size
-	info, err := os.Stat(outputPath)
-	if err == nil {
-		pdfRecord.Size = info.Size()
-	}
-
-	err = s.Repo.Create(pdfRecord)
-	if err != nil {
+	if err := s.commitFile(ctx, tmpPath, pdfRecord, ""); err != nil {
+		os.Remove(tmpPath)
 		return nil, err
 	}
 
 	return pdfRecord, nil
 }
 
-func (s *PdfService) UploadPDF(file io.Reader, header *multipart.FileHeader) (*model.PdfFile, error) {
+func (s *PdfService) UploadPDF(ctx context.Context, file io.Reader, header *multipart.FileHeader) (*model.PdfFile, error) {
+	start := time.Now()
+	record, err := s.uploadPDF(ctx, file, header)
+	observeStore("upload", start, err)
+	return record, err
+}
+
+func (s *PdfService) uploadPDF(ctx context.Context, file io.Reader, header *multipart.FileHeader) (*model.PdfFile, error) {
 	ext := filepath.Ext(header.Filename)
 	if ext != ".pdf" {
-		return nil, fmt.Errorf("hanya menerima file dengan ekstensi .pdf")
+		return nil, ErrInvalidUploadName
 	}
 
 	uniqueName := fmt.Sprintf("upload_%s_%d%s", time.Now().Format("20060102"), time.Now().UnixNano(), ext)
-	outputPath := filepath.Join("uploads", "pdf", uniqueName)
-
-	dst, err := os.Create(outputPath)
+	tmpPath, size, err := s.writeTemp(func(w io.Writer) error {
+		_, err := io.Copy(w, file)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, file); err != nil {
-		return nil, err
-	}
 
 	originalName := header.Filename
-	filepathStr := fmt.Sprintf("/uploads/pdf/%s", uniqueName)
-
 	pdfRecord := &model.PdfFile{
 		Filename:     uniqueName,
 		OriginalName: &originalName,
-		Filepath:     filepathStr,
-		Size:         header.Size,
-		Status:       model.StatusUploaded,
+		Filepath:     fmt.Sprintf("/uploads/pdf/%s", uniqueName),
+		Size:         size,
+		Status:       model.StatusPendingScan,
 	}
-
-	err = s.Repo.Create(pdfRecord)
-	if err != nil {
+	if err := s.commitFile(ctx, tmpPath, pdfRecord, ""); err != nil {
+		os.Remove(tmpPath)
 		return nil, err
 	}
 
+	s.scanUpload(ctx, pdfRecord)
 	return pdfRecord, nil
 }
 
-func (s *PdfService) ListPDFs(status string, page, limit int) ([]model.PdfFile, int64, error) {
+func (s *PdfService) ListPDFs(ctx context.Context, status string, page, limit int) ([]model.PdfFile, int64, error) {
 	if page < 1 {
 		page = 1
 	}
 	if limit < 1 {
 		limit = 10
 	}
-	return s.Repo.FindAll(status, page, limit)
+	return s.Repo.FindAll(ctx, status, page, limit)
+}
+
+func (s *PdfService) DeletePDF(ctx context.Context, id int64) (*model.PdfFile, error) {
+	err := s.Repo.SoftDelete(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Repo.FindByID(ctx, id)
+}
+
+// StoreFile moves a fully written local file into PDF storage, records it and
+// runs the malware scan (UPLOADED when clean).
+// Caller-provided fields on record (original name, provenance) are kept.
+func (s *PdfService) StoreFile(ctx context.Context, localPath string, record *model.PdfFile) (*model.PdfFile, error) {
+	start := time.Now()
+	source := "chunked"
+	if record.SourceURL != nil {
+		source = "import"
+	}
+	stored, err := s.storeFile(ctx, localPath, record, "")
+	observeStore(source, start, err)
+	return stored, err
+}
+
+// StoreUpload is StoreFile for a resumable upload: the session is marked
+// COMPLETED in the same transaction as the insert. If it fails, localPath is
+// put back so the upload can be completed again.
+func (s *PdfService) StoreUpload(ctx context.Context, localPath string, record *model.PdfFile, sessionID string) (*model.PdfFile, error) {
+	start := time.Now()
+	stored, err := s.storeFile(ctx, localPath, record, sessionID)
+	observeStore("chunked", start, err)
+	return stored, err
 }
 
-func (s *PdfService) DeletePDF(id int64) (*model.PdfFile, error) {
-	err := s.Repo.SoftDelete(id)
+func (s *PdfService) storeFile(ctx context.Context, localPath string, record *model.PdfFile, sessionID string) (*model.PdfFile, error) {
+	uniqueName := fmt.Sprintf("upload_%s_%d.pdf", time.Now().Format("20060102"), time.Now().UnixNano())
+
+	// localPath may be on another filesystem: copy next to the final path first
+	tmp, err := os.CreateTemp(s.Dir, tempPrefix+"*")
 	if err != nil {
 		return nil, err
 	}
-	return s.Repo.FindByID(id)
+	tmp.Close()
+	if err := moveFile(localPath, tmp.Name()); err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	record.Filename = uniqueName
+	record.Filepath = fmt.Sprintf("/uploads/pdf/%s", uniqueName)
+	record.Size = info.Size()
+	record.Status = model.StatusPendingScan
+	if err := s.commitFile(ctx, tmp.Name(), record, sessionID); err != nil {
+		if merr := moveFile(tmp.Name(), localPath); merr != nil {
+			os.Remove(tmp.Name())
+			slog.ErrorContext(ctx, "failed to restore file after failed store", "file", localPath, "error", merr)
+		}
+		return nil, err
+	}
+
+	s.scanUpload(ctx, record)
+	return record, nil
+}
+
+// tempPrefix marks files in Dir that are still being written. They are never
+// referenced by a row; reconcile removes stale ones.
+const tempPrefix = ".tmp-"
+
+// writeTemp writes a new file in Dir through write and syncs it to disk.
+// The file is removed again on any error.
+func (s *PdfService) writeTemp(write func(w io.Writer) error) (string, int64, error) {
+	f, err := os.CreateTemp(s.Dir, tempPrefix+"*")
+	if err != nil {
+		return "", 0, err
+	}
+	counter := &countingWriter{w: f}
+	err = write(counter)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", 0, err
+	}
+	return f.Name(), counter.n, nil
+}
+
+// commitFile inserts record and renames tmpPath to its final name in the same
+// DB transaction, so a row is only visible once its file is complete. With
+// sessionID set the upload session is completed in that transaction too.
+// On failure no row is left and the file is back at tmpPath for the caller
+// to remove or restore; a crash between the rename and the commit leaves an
+// orphan file for reconcile.
+func (s *PdfService) commitFile(ctx context.Context, tmpPath string, record *model.PdfFile, sessionID string) error {
+	finalPath := filepath.Join(s.Dir, record.Filename)
+	renamed := false
+	publish := func() error {
+		if err := os.Rename(tmpPath, finalPath); err != nil {
+			return err
+		}
+		renamed = true
+		return nil
+	}
+
+	var err error
+	if sessionID != "" {
+		err = s.Repo.CreateFromUpload(ctx, record, sessionID, publish)
+	} else {
+		err = s.Repo.CreateWithFile(ctx, record, publish)
+	}
+	if err != nil && renamed {
+		if rerr := os.Rename(finalPath, tmpPath); rerr != nil {
+			os.Remove(finalPath)
+		}
+	}
+	return err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func observeStore(source string, start time.Time, err error) {
+	metrics.PdfUploads.Inc(source, metrics.Outcome(err, false))
+	if err == nil {
+		metrics.PdfUploadDuration.Observe(time.Since(start).Seconds(), source)
+	}
+}
+
+// moveFile renames src to dst, falling back to copy+remove across filesystems.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// scanUpload runs the scanner on a PENDING_SCAN record. Clean files become UPLOADED,
+// infected files are moved out of the served directory and marked QUARANTINED.
+// On scanner errors the record stays PENDING_SCAN and is retried by RunRescanLoop;
+// if the verdict cannot be saved a quarantined file is moved back so it can be.
+func (s *PdfService) scanUpload(ctx context.Context, record *model.PdfFile) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Minute)
+	defer cancel()
+
+	path := filepath.Join(s.Dir, record.Filename)
+	f, err := os.Open(path)
+	if err != nil {
+		slog.ErrorContext(ctx, "scan failed", "pdf_id", record.ID, "file", record.Filename, "error", err)
+		return
+	}
+	result, err := s.Scanner.Scan(ctx, f)
+	f.Close()
+	if err != nil {
+		slog.WarnContext(ctx, "scan failed, keeping PENDING_SCAN", "pdf_id", record.ID, "file", record.Filename, "error", err)
+		return
+	}
+
+	status, verdict := model.StatusUploaded, "CLEAN"
+	quarantined := filepath.Join(s.QuarantineDir, record.Filename)
+	if !result.Clean {
+		status, verdict = model.StatusQuarantined, result.Signature
+		if err := os.MkdirAll(s.QuarantineDir, 0700); err != nil {
+			slog.ErrorContext(ctx, "failed to create quarantine dir", "error", err)
+			return
+		}
+		if err := moveFile(path, quarantined); err != nil {
+			slog.ErrorContext(ctx, "failed to quarantine file", "pdf_id", record.ID, "file", record.Filename, "error", err)
+			return
+		}
+		slog.WarnContext(ctx, "file quarantined", "pdf_id", record.ID, "file", record.Filename, "signature", result.Signature)
+	}
+
+	if err := s.Repo.UpdateScanResult(ctx, record.ID, status, verdict); err != nil {
+		slog.ErrorContext(ctx, "failed to save scan result", "pdf_id", record.ID, "file", record.Filename, "error", err)
+		if status == model.StatusQuarantined {
+			// Row masih PENDING_SCAN: file harus ada di Dir agar bisa discan ulang
+			if err := moveFile(quarantined, path); err != nil {
+				slog.ErrorContext(ctx, "failed to move file back from quarantine", "pdf_id", record.ID, "file", record.Filename, "error", err)
+			}
+		}
+		return
+	}
+	record.Status = status
+	record.ScanResult = &verdict
+}
+
+// RescanPending retries files left in PENDING_SCAN (e.g. scanner was down).
+// Rows whose file is missing are skipped; `server reconcile` reports them.
+func (s *PdfService) RescanPending(ctx context.Context) {
+	var afterID int64
+	for {
+		files, err := s.Repo.FindPendingScan(ctx, afterID, rescanBatchSize)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to list pending scans", "error", err)
+			return
+		}
+		for i := range files {
+			afterID = files[i].ID
+			if _, err := os.Stat(filepath.Join(s.Dir, files[i].Filename)); errors.Is(err, fs.ErrNotExist) {
+				slog.DebugContext(ctx, "pending scan skipped, file missing", "pdf_id", files[i].ID, "file", files[i].Filename)
+				continue
+			}
+			s.scanUpload(ctx, &files[i])
+		}
+		if len(files) < rescanBatchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// RunRescanLoop calls RescanPending every interval until ctx is done.
+func (s *PdfService) RunRescanLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RescanPending(ctx)
+		}
+	}
+}
+
+// DownloadPath returns the record and local path of a stored file. The
+// record is also returned with ErrFileNotClean so the refusal can be audited.
+func (s *PdfService) DownloadPath(ctx context.Context, filename string) (*model.PdfFile, string, error) {
+	pdf, err := s.Repo.FindByFilename(ctx, filename)
+	if err == sql.ErrNoRows {
+		return nil, "", fmt.Errorf("file not found")
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	if pdf.Status == model.StatusPendingScan || pdf.Status == model.StatusQuarantined {
+		return pdf, "", ErrFileNotClean
+	}
+
+	return pdf, filepath.Join(s.Dir, pdf.Filename), nil
 }
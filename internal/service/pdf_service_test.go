@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/scanner"
+	"strings"
+	"testing"
+)
+
+func newTestPdfService(t *testing.T, sc scanner.Scanner) (*PdfService, *fakePdfStore) {
+	t.Helper()
+	cfg := testConfig(t.TempDir())
+	if err := os.MkdirAll(cfg.Storage.PdfDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	store := newFakePdfStore()
+	return NewPdfService(store, sc, cfg), store
+}
+
+func upload(t *testing.T, s *PdfService, name, content string) (*model.PdfFile, error) {
+	t.Helper()
+	return s.UploadPDF(context.Background(), strings.NewReader(content), &multipart.FileHeader{Filename: name, Size: int64(len(content))})
+}
+
+func TestUploadPDFScanVerdict(t *testing.T) {
+	tests := []struct {
+		name       string
+		scanner    scanner.Scanner
+		status     model.PdfStatus
+		quarantine bool
+	}{
+		{name: "clean", scanner: stubScanner{result: scanner.Result{Clean: true}}, status: model.StatusUploaded},
+		{name: "infected", scanner: stubScanner{result: scanner.Result{Signature: "Eicar-Test-Signature"}}, status: model.StatusQuarantined, quarantine: true},
+		{name: "scanner down", scanner: stubScanner{err: errors.New("clamd: connection refused")}, status: model.StatusPendingScan},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store := newTestPdfService(t, tt.scanner)
+
+			pdf, err := upload(t, s, "report.pdf", "%PDF-1.4 test")
+			if err != nil {
+				t.Fatalf("UploadPDF: %v", err)
+			}
+			stored, _ := store.FindByID(context.Background(), pdf.ID)
+			if stored.Status != tt.status || pdf.Status != tt.status {
+				t.Errorf("status = %s (returned %s), want %s", stored.Status, pdf.Status, tt.status)
+			}
+
+			_, servedErr := os.Stat(filepath.Join(s.Dir, pdf.Filename))
+			_, quarantinedErr := os.Stat(filepath.Join(s.QuarantineDir, pdf.Filename))
+			if tt.quarantine {
+				if servedErr == nil || quarantinedErr != nil {
+					t.Errorf("infected file not moved to quarantine (served: %v, quarantine: %v)", servedErr, quarantinedErr)
+				}
+				if stored.ScanResult == nil || *stored.ScanResult != "Eicar-Test-Signature" {
+					t.Errorf("scan_result = %v, want the signature", stored.ScanResult)
+				}
+			} else if servedErr != nil {
+				t.Errorf("file missing from storage: %v", servedErr)
+			}
+		})
+	}
+}
+
+func TestUploadPDFRejectsOtherExtensions(t *testing.T) {
+	s, store := newTestPdfService(t, nil)
+
+	if _, err := upload(t, s, "setup.exe", "MZ"); !errors.Is(err, ErrInvalidUploadName) {
+		t.Fatalf("err = %v, want ErrInvalidUploadName", err)
+	}
+	if len(store.files) != 0 {
+		t.Errorf("%d rows created for a rejected file", len(store.files))
+	}
+	if entries, _ := os.ReadDir(s.Dir); len(entries) != 0 {
+		t.Errorf("files left in storage: %v", entries)
+	}
+}
+
+func TestUploadPDFLeavesNothingWhenInsertFails(t *testing.T) {
+	s, store := newTestPdfService(t, nil)
+	s.Repo = failingCreateStore{store}
+
+	if _, err := upload(t, s, "report.pdf", "%PDF-1.4 test"); err == nil {
+		t.Fatal("UploadPDF succeeded, want the insert error")
+	}
+	if entries, _ := os.ReadDir(s.Dir); len(entries) != 0 {
+		t.Errorf("files left in storage after failed insert: %v", entries)
+	}
+}
+
+// failingCreateStore publishes the file and then fails, like a failed commit.
+type failingCreateStore struct{ *fakePdfStore }
+
+func (f failingCreateStore) CreateWithFile(ctx context.Context, pdf *model.PdfFile, publish func() error) error {
+	if err := publish(); err != nil {
+		return err
+	}
+	return errors.New("commit failed")
+}
+
+func TestDownloadPathRefusesUncleanFiles(t *testing.T) {
+	for _, status := range []model.PdfStatus{model.StatusPendingScan, model.StatusQuarantined} {
+		t.Run(string(status), func(t *testing.T) {
+			s, store := newTestPdfService(t, nil)
+			store.put(model.PdfFile{Filename: "upload_1.pdf", Status: status})
+
+			pdf, path, err := s.DownloadPath(context.Background(), "upload_1.pdf")
+			if !errors.Is(err, ErrFileNotClean) {
+				t.Fatalf("err = %v, want ErrFileNotClean", err)
+			}
+			if pdf == nil || path != "" {
+				t.Errorf("got record %v and path %q, want the record (for audit) and no path", pdf, path)
+			}
+		})
+	}
+
+	s, store := newTestPdfService(t, nil)
+	store.put(model.PdfFile{Filename: "upload_2.pdf", Status: model.StatusUploaded})
+	if _, path, err := s.DownloadPath(context.Background(), "upload_2.pdf"); err != nil || path != filepath.Join(s.Dir, "upload_2.pdf") {
+		t.Errorf("clean file: path %q, err %v", path, err)
+	}
+}
+
+func TestScanUploadKeepsFileScannableWhenVerdictNotSaved(t *testing.T) {
+	s, store := newTestPdfService(t, stubScanner{result: scanner.Result{Signature: "Eicar-Test-Signature"}})
+	pdf := store.put(model.PdfFile{Filename: "upload_1.pdf", Status: model.StatusPendingScan})
+	if err := os.WriteFile(filepath.Join(s.Dir, pdf.Filename), []byte("%PDF-1.4 test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store.failScanUpdate = errors.New("connection reset")
+	s.RescanPending(context.Background())
+	if _, err := os.Stat(filepath.Join(s.Dir, pdf.Filename)); err != nil {
+		t.Fatalf("file not moved back after failed update: %v", err)
+	}
+
+	store.failScanUpdate = nil
+	s.RescanPending(context.Background())
+	stored, _ := store.FindByID(context.Background(), pdf.ID)
+	if stored.Status != model.StatusQuarantined {
+		t.Errorf("status after retry = %s, want QUARANTINED", stored.Status)
+	}
+	if _, err := os.Stat(filepath.Join(s.QuarantineDir, pdf.Filename)); err != nil {
+		t.Errorf("file not in quarantine after retry: %v", err)
+	}
+}
+
+func TestRescanPendingPagesPastMissingFiles(t *testing.T) {
+	s, store := newTestPdfService(t, stubScanner{result: scanner.Result{Clean: true}})
+	// Satu halaman penuh row yang filenya hilang tidak boleh menghalangi row sesudahnya
+	for i := range rescanBatchSize + 5 {
+		store.put(model.PdfFile{Filename: fmt.Sprintf("missing_%d.pdf", i), Status: model.StatusPendingScan})
+	}
+	pdf := store.put(model.PdfFile{Filename: "upload_new.pdf", Status: model.StatusPendingScan})
+	if err := os.WriteFile(filepath.Join(s.Dir, pdf.Filename), []byte("%PDF-1.4 test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s.RescanPending(context.Background())
+	stored, _ := store.FindByID(context.Background(), pdf.ID)
+	if stored.Status != model.StatusUploaded {
+		t.Errorf("status = %s, want UPLOADED", stored.Status)
+	}
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPreviewDPI = 96
+	MinPreviewDPI     = 36
+	MaxPreviewDPI     = 300
+
+	// Thumbnail dibatasi sisi terpanjangnya, bukan DPI
+	thumbnailMaxSide = 320
+	renderTimeout    = 30 * time.Second
+)
+
+var (
+	ErrPreviewUnavailable = errors.New("preview renderer not available")
+	ErrPageNotFound       = errors.New("page not found")
+	ErrInvalidDPI         = fmt.Errorf("dpi must be between %d and %d", MinPreviewDPI, MaxPreviewDPI)
+)
+
+// RenderOptions describes a single page rasterization.
+// MaxSide > 0 scales the image so its longest side fits, ignoring DPI.
+type RenderOptions struct {
+	Page    int
+	DPI     int
+	MaxSide int
+}
+
+// PageRenderer rasterizes one page of a PDF into a PNG file at dst.
+type PageRenderer interface {
+	Name() string
+	RenderPNG(ctx context.Context, src, dst string, opts RenderOptions) error
+}
+
+// PdftoppmRenderer uses poppler's pdftoppm binary.
+type PdftoppmRenderer struct {
+	Bin string
+}
+
+func (r *PdftoppmRenderer) Name() string { return "pdftoppm" }
+
+func (r *PdftoppmRenderer) RenderPNG(ctx context.Context, src, dst string, opts RenderOptions) error {
+	page := strconv.Itoa(opts.Page)
+	args := []string{"-png", "-singlefile", "-f", page, "-l", page}
+	if opts.MaxSide > 0 {
+		args = append(args, "-scale-to", strconv.Itoa(opts.MaxSide))
+	} else {
+		args = append(args, "-r", strconv.Itoa(opts.DPI))
+	}
+	// pdftoppm menambahkan ".png" sendiri ke prefix output
+	args = append(args, src, strings.TrimSuffix(dst, ".png"))
+
+	out, err := exec.CommandContext(ctx, r.Bin, args...).CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(out), "Wrong page range") {
+			return ErrPageNotFound
+		}
+		return fmt.Errorf("pdftoppm: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// MutoolRenderer uses MuPDF's mutool binary.
+type MutoolRenderer struct {
+	Bin string
+}
+
+func (r *MutoolRenderer) Name() string { return "mutool" }
+
+func (r *MutoolRenderer) RenderPNG(ctx context.Context, src, dst string, opts RenderOptions) error {
+	args := []string{"draw", "-q", "-o", dst}
+	if opts.MaxSide > 0 {
+		side := strconv.Itoa(opts.MaxSide)
+		args = append(args, "-w", side, "-h", side)
+	} else {
+		args = append(args, "-r", strconv.Itoa(opts.DPI))
+	}
+	args = append(args, src, strconv.Itoa(opts.Page))
+
+	out, err := exec.CommandContext(ctx, r.Bin, args...).CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(out), "out of range") {
+			return ErrPageNotFound
+		}
+		return fmt.Errorf("mutool: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// NewPageRenderer picks the renderer named in cfg.Preview.Renderer, or the
+// first one found on PATH. Returns nil when nothing is installed.
+func NewPageRenderer(cfg *config.Config) PageRenderer {
+	candidates := []string{"pdftoppm", "mutool"}
+	if name := cfg.Preview.Renderer; name != "" {
+		candidates = []string{name}
+	}
+
+	for _, name := range candidates {
+		bin, err := exec.LookPath(name)
+		if err != nil {
+			continue
+		}
+		switch filepath.Base(name) {
+		case "pdftoppm":
+			return &PdftoppmRenderer{Bin: bin}
+		case "mutool":
+			return &MutoolRenderer{Bin: bin}
+		}
+	}
+	return nil
+}
+
+type PreviewService struct {
+	Repo     PdfStore
+	Renderer PageRenderer
+	Dir      string // storage dir of the PDFs, previews are cached inside it
+
+	locks keyedMutex
+}
+
+func NewPreviewService(repo PdfStore, renderer PageRenderer, cfg *config.Config) *PreviewService {
+	return &PreviewService{
+		Repo:     repo,
+		Renderer: renderer,
+		Dir:      cfg.Storage.PdfDir,
+	}
+}
+
+// Thumbnail returns the path of the cached first-page thumbnail, rendering it if needed.
+func (s *PreviewService) Thumbnail(ctx context.Context, id int64) (string, error) {
+	return s.render(ctx, id, "thumb.png", RenderOptions{Page: 1, MaxSide: thumbnailMaxSide})
+}
+
+// Page returns the path of the cached PNG for the given page at the given DPI.
+func (s *PreviewService) Page(ctx context.Context, id int64, page, dpi int) (string, error) {
+	if page < 1 {
+		return "", ErrPageNotFound
+	}
+	if dpi == 0 {
+		dpi = DefaultPreviewDPI
+	}
+	if dpi < MinPreviewDPI || dpi > MaxPreviewDPI {
+		return "", ErrInvalidDPI
+	}
+	name := fmt.Sprintf("page-%d-%ddpi.png", page, dpi)
+	return s.render(ctx, id, name, RenderOptions{Page: page, DPI: dpi})
+}
+
+func (s *PreviewService) render(ctx context.Context, id int64, name string, opts RenderOptions) (string, error) {
+	if s.Renderer == nil {
+		return "", ErrPreviewUnavailable
+	}
+
+	pdf, err := s.Repo.FindByID(ctx, id)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("file not found")
+	} else if err != nil {
+		return "", err
+	}
+	if pdf.Status == model.StatusDeleted {
+		return "", fmt.Errorf("file not found")
+	}
+	if pdf.Status == model.StatusPendingScan || pdf.Status == model.StatusQuarantined {
+		return "", ErrFileNotClean
+	}
+
+	src := filepath.Join(s.Dir, pdf.Filename)
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return "", fmt.Errorf("file not found")
+	}
+
+	// Cache disimpan di samping file: <storage>/.previews/<nama file>/
+	cacheDir := filepath.Join(s.Dir, ".previews", strings.TrimSuffix(pdf.Filename, filepath.Ext(pdf.Filename)))
+	dst := filepath.Join(cacheDir, name)
+
+	// Satu render per file cache pada satu waktu
+	unlock := s.locks.Lock(dst)
+	defer unlock()
+
+	if info, err := os.Stat(dst); err == nil && !info.ModTime().Before(srcInfo.ModTime()) {
+		return dst, nil
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, renderTimeout)
+	defer cancel()
+
+	// Render ke file sementara lalu rename, supaya tidak ada PNG setengah jadi di cache
+	tmp := filepath.Join(cacheDir, fmt.Sprintf(".tmp-%d-%s", time.Now().UnixNano(), name))
+	if err := s.Renderer.RenderPNG(ctx, src, tmp, opts); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if _, err := os.Stat(tmp); err != nil {
+		// Beberapa renderer keluar dengan status 0 untuk halaman di luar jangkauan
+		return "", ErrPageNotFound
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	return dst, nil
+}
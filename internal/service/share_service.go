@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/validation"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Riwayat akses yang ditampilkan per link
+const shareAccessLimit = 100
+
+var (
+	ErrShareLinkNotFound     = errors.New("share link not found")
+	ErrShareLinkRevoked      = errors.New("share link has been revoked")
+	ErrShareLinkExpired      = errors.New("share link has expired")
+	ErrShareLinkUsedUp       = errors.New("share link download limit reached")
+	ErrSharePasswordRequired = errors.New("share link is password protected")
+	ErrShareWrongPassword    = errors.New("wrong share link password")
+	ErrFileDeleted           = errors.New("file has been deleted")
+)
+
+// ShareService manages expiring public links to single PDFs for people
+// without an account. Only the SHA-256 of a link token is stored.
+type ShareService struct {
+	Repo ShareLinkStore
+	Pdf  *PdfService
+
+	BaseURL             string
+	DefaultTTL          time.Duration
+	MaxTTL              time.Duration
+	MaxPasswordFailures int
+}
+
+func NewShareService(repo ShareLinkStore, pdf *PdfService, cfg *config.Config) *ShareService {
+	return &ShareService{
+		Repo:                repo,
+		Pdf:                 pdf,
+		BaseURL:             strings.TrimRight(cfg.Server.BaseURL, "/"),
+		DefaultTTL:          cfg.Share.DefaultTTL,
+		MaxTTL:              cfg.Share.MaxTTL,
+		MaxPasswordFailures: cfg.Share.MaxPasswordFailures,
+	}
+}
+
+// Create issues a link to pdfID. The token and URL are only returned here.
+func (s *ShareService) Create(ctx context.Context, pdfID, userID int64, req model.CreateShareLinkRequest) (*model.CreatedShareLink, error) {
+	now := time.Now()
+	if err := validation.ValidateCreateShareLink(req, now, s.MaxTTL).Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.findPdf(ctx, pdfID); err != nil {
+		return nil, err
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	link := model.ShareLink{
+		PdfFileID:    pdfID,
+		CreatedBy:    &userID,
+		TokenHash:    hashToken(token),
+		MaxDownloads: req.MaxDownloads,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(s.DefaultTTL),
+	}
+	if req.ExpiresAt != nil {
+		link.ExpiresAt = *req.ExpiresAt
+	}
+	if req.Password != "" {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		pw := string(hashed)
+		link.PasswordHash = &pw
+		link.HasPassword = true
+	}
+
+	if err := s.Repo.Create(ctx, &link); err != nil {
+		return nil, err
+	}
+	return &model.CreatedShareLink{ShareLink: link, Token: token, URL: s.BaseURL + "/s/" + token}, nil
+}
+
+// List returns the active links of pdfID.
+func (s *ShareService) List(ctx context.Context, pdfID int64) ([]model.ShareLink, error) {
+	return s.Repo.FindActiveByPdf(ctx, pdfID, time.Now())
+}
+
+func (s *ShareService) Revoke(ctx context.Context, pdfID, id int64) (*model.ShareLink, error) {
+	link, err := s.Repo.Revoke(ctx, id, pdfID)
+	if err == sql.ErrNoRows {
+		return nil, ErrShareLinkNotFound
+	}
+	return link, err
+}
+
+// Accesses returns the latest accesses of a link of pdfID, newest first.
+func (s *ShareService) Accesses(ctx context.Context, pdfID, id int64) ([]model.ShareLinkAccess, error) {
+	return s.Repo.FindAccesses(ctx, id, pdfID, shareAccessLimit)
+}
+
+// Open checks a link token (and password) and counts one download. It returns
+// the file and its local path; the link is returned whenever the token is
+// known so the caller can audit the attempt. Every attempt on a known link
+// is recorded in share_link_accesses.
+func (s *ShareService) Open(ctx context.Context, token, password string, client model.ClientInfo) (*model.ShareLink, *model.PdfFile, string, error) {
+	link, err := s.Repo.FindByHash(ctx, hashToken(token))
+	if err == sql.ErrNoRows {
+		return nil, nil, "", ErrShareLinkNotFound
+	} else if err != nil {
+		return nil, nil, "", err
+	}
+
+	pdf, path, outcome, err := s.open(ctx, link, password)
+	s.recordAccess(ctx, link.ID, client, outcome)
+	return link, pdf, path, err
+}
+
+func (s *ShareService) open(ctx context.Context, link *model.ShareLink, password string) (*model.PdfFile, string, string, error) {
+	now := time.Now()
+	switch {
+	case link.RevokedAt != nil:
+		return nil, "", model.ShareRevoked, ErrShareLinkRevoked
+	case !now.Before(link.ExpiresAt):
+		return nil, "", model.ShareExpired, ErrShareLinkExpired
+	case link.MaxDownloads != nil && link.DownloadCount >= *link.MaxDownloads:
+		return nil, "", model.ShareLimitReached, ErrShareLinkUsedUp
+	}
+
+	if link.PasswordHash != nil {
+		if password == "" {
+			return nil, "", model.SharePasswordNeeded, ErrSharePasswordRequired
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(*link.PasswordHash), []byte(password)); err != nil {
+			revoked, err := s.Repo.RecordFailedPassword(ctx, link.ID, s.MaxPasswordFailures, now)
+			if err != nil {
+				return nil, "", model.ShareWrongPassword, err
+			}
+			if revoked {
+				slog.WarnContext(ctx, "share link revoked after too many wrong passwords", "share_link_id", link.ID, "pdf_id", link.PdfFileID)
+			}
+			return nil, "", model.ShareWrongPassword, ErrShareWrongPassword
+		}
+	}
+
+	pdf, err := s.findPdf(ctx, link.PdfFileID)
+	if err != nil {
+		return pdf, "", model.ShareFileUnavailable, err
+	}
+
+	ok, err := s.Repo.ClaimDownload(ctx, link.ID, now)
+	if err != nil {
+		return pdf, "", model.ShareLimitReached, err
+	}
+	if !ok {
+		// Dipakai habis / dicabut oleh request lain sejak FindByHash
+		return pdf, "", model.ShareLimitReached, ErrShareLinkUsedUp
+	}
+	return pdf, filepath.Join(s.Pdf.Dir, pdf.Filename), model.ShareDownloaded, nil
+}
+
+// findPdf returns pdfID if it can be shared: not deleted and past the malware scan.
+func (s *ShareService) findPdf(ctx context.Context, pdfID int64) (*model.PdfFile, error) {
+	pdf, err := s.Pdf.Repo.FindByID(ctx, pdfID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("file not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	switch pdf.Status {
+	case model.StatusDeleted:
+		return pdf, ErrFileDeleted
+	case model.StatusPendingScan, model.StatusQuarantined:
+		return pdf, ErrFileNotClean
+	}
+	return pdf, nil
+}
+
+func (s *ShareService) recordAccess(ctx context.Context, linkID int64, client model.ClientInfo, outcome string) {
+	access := &model.ShareLinkAccess{
+		ShareLinkID: linkID,
+		IPAddress:   client.IP,
+		UserAgent:   truncateRunes(client.UserAgent, 255),
+		Outcome:     outcome,
+		AccessedAt:  time.Now(),
+	}
+	if err := s.Repo.RecordAccess(context.WithoutCancel(ctx), access); err != nil {
+		slog.ErrorContext(ctx, "failed to record share link access", "share_link_id", linkID, "error", err)
+	}
+}
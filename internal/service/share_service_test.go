@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"pdf-management-system/internal/model"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type shareFixture struct {
+	svc    *ShareService
+	shares *fakeShareStore
+	pdfs   *fakePdfStore
+	pdf    *model.PdfFile
+}
+
+func newShareFixture(t *testing.T) *shareFixture {
+	t.Helper()
+	cfg := testConfig(t.TempDir())
+	cfg.Share.MaxPasswordFailures = 3
+	pdfs, shares := newFakePdfStore(), &fakeShareStore{}
+	pdf := pdfs.put(model.PdfFile{Filename: "upload_1.pdf", Status: model.StatusUploaded})
+	return &shareFixture{
+		svc:    NewShareService(shares, NewPdfService(pdfs, nil, cfg), cfg),
+		shares: shares,
+		pdfs:   pdfs,
+		pdf:    pdf,
+	}
+}
+
+// link stores a link with token tok to the fixture's PDF.
+func (f *shareFixture) link(tok, password string, maxDownloads *int, expiresAt time.Time) *model.ShareLink {
+	l := &model.ShareLink{PdfFileID: f.pdf.ID, TokenHash: hashToken(tok), MaxDownloads: maxDownloads, ExpiresAt: expiresAt}
+	if password != "" {
+		hashed, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		pw := string(hashed)
+		l.PasswordHash = &pw
+	}
+	f.shares.Create(context.Background(), l)
+	return l
+}
+
+func (f *shareFixture) outcomes() []string {
+	var outcomes []string
+	for _, a := range f.shares.accesses {
+		outcomes = append(outcomes, a.Outcome)
+	}
+	return outcomes
+}
+
+func TestShareOpen(t *testing.T) {
+	ctx := context.Background()
+	client := model.ClientInfo{IP: "192.0.2.1", UserAgent: "curl/8"}
+	later := time.Now().Add(time.Hour)
+
+	t.Run("download limit", func(t *testing.T) {
+		f := newShareFixture(t)
+		one := 1
+		l := f.link("tok", "", &one, later)
+
+		link, pdf, path, err := f.svc.Open(ctx, "tok", "", client)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		if link.ID != l.ID || pdf.ID != f.pdf.ID || path != filepath.Join(f.svc.Pdf.Dir, "upload_1.pdf") {
+			t.Errorf("Open = link %d, pdf %d, path %q", link.ID, pdf.ID, path)
+		}
+		if _, _, _, err := f.svc.Open(ctx, "tok", "", client); !errors.Is(err, ErrShareLinkUsedUp) {
+			t.Errorf("second download: err = %v, want ErrShareLinkUsedUp", err)
+		}
+		if got := f.outcomes(); strings.Join(got, ",") != "DOWNLOADED,LIMIT_REACHED" {
+			t.Errorf("recorded outcomes = %v", got)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		f := newShareFixture(t)
+		link, _, _, err := f.svc.Open(ctx, "nope", "", client)
+		if !errors.Is(err, ErrShareLinkNotFound) || link != nil {
+			t.Errorf("Open = %v, %v; want ErrShareLinkNotFound", link, err)
+		}
+		if len(f.shares.accesses) != 0 {
+			t.Error("access recorded for an unknown token")
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		f := newShareFixture(t)
+		f.link("tok", "", nil, time.Now().Add(-time.Second))
+		link, _, _, err := f.svc.Open(ctx, "tok", "", client)
+		if !errors.Is(err, ErrShareLinkExpired) || link == nil {
+			t.Errorf("Open = %v, %v; want the link and ErrShareLinkExpired", link, err)
+		}
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		f := newShareFixture(t)
+		l := f.link("tok", "", nil, later)
+		f.svc.Revoke(ctx, f.pdf.ID, l.ID)
+		if _, _, _, err := f.svc.Open(ctx, "tok", "", client); !errors.Is(err, ErrShareLinkRevoked) {
+			t.Errorf("err = %v, want ErrShareLinkRevoked", err)
+		}
+	})
+
+	t.Run("password", func(t *testing.T) {
+		f := newShareFixture(t)
+		f.link("tok", "rahasia123", nil, later)
+
+		if _, _, _, err := f.svc.Open(ctx, "tok", "", client); !errors.Is(err, ErrSharePasswordRequired) {
+			t.Errorf("no password: err = %v, want ErrSharePasswordRequired", err)
+		}
+		if _, _, _, err := f.svc.Open(ctx, "tok", "salah", client); !errors.Is(err, ErrShareWrongPassword) {
+			t.Errorf("wrong password: err = %v, want ErrShareWrongPassword", err)
+		}
+		if _, _, path, err := f.svc.Open(ctx, "tok", "rahasia123", client); err != nil || path == "" {
+			t.Errorf("right password: path %q, err %v", path, err)
+		}
+	})
+
+	t.Run("revoked after too many wrong passwords", func(t *testing.T) {
+		f := newShareFixture(t)
+		f.link("tok", "rahasia123", nil, later)
+		for range 3 {
+			f.svc.Open(ctx, "tok", "salah", client)
+		}
+		if _, _, _, err := f.svc.Open(ctx, "tok", "rahasia123", client); !errors.Is(err, ErrShareLinkRevoked) {
+			t.Errorf("right password after lockout: err = %v, want ErrShareLinkRevoked", err)
+		}
+	})
+
+	t.Run("file not shareable", func(t *testing.T) {
+		for status, want := range map[model.PdfStatus]error{
+			model.StatusDeleted:     ErrFileDeleted,
+			model.StatusPendingScan: ErrFileNotClean,
+			model.StatusQuarantined: ErrFileNotClean,
+		} {
+			f := newShareFixture(t)
+			f.pdfs.files[f.pdf.ID].Status = status
+			f.link("tok", "", nil, later)
+			if _, _, _, err := f.svc.Open(ctx, "tok", "", client); !errors.Is(err, want) {
+				t.Errorf("%s: err = %v, want %v", status, err, want)
+			}
+			if l, _ := f.shares.FindByHash(ctx, hashToken("tok")); l.DownloadCount != 0 {
+				t.Errorf("%s: download counted for an unavailable file", status)
+			}
+		}
+	})
+}
+
+func TestShareOpenRecordsUserAgentOnRuneBoundary(t *testing.T) {
+	f := newShareFixture(t)
+	f.link("tok", "", nil, time.Now().Add(-time.Minute))
+
+	// 254 byte ASCII lalu karakter 3 byte: potongan byte ke-255 akan memecah "€"
+	ua := strings.Repeat("a", 254) + strings.Repeat("€", 10)
+	f.svc.Open(context.Background(), "tok", "", model.ClientInfo{IP: "192.0.2.1", UserAgent: ua})
+
+	got := f.shares.accesses[0].UserAgent
+	if !utf8.ValidString(got) {
+		t.Fatalf("user agent truncated inside a UTF-8 sequence: %q", got[250:])
+	}
+	if utf8.RuneCountInString(got) != 255 || !strings.HasSuffix(got, "a€") {
+		t.Errorf("user agent = %d runes ending in %q, want the first 255 characters", utf8.RuneCountInString(got), got[len(got)-4:])
+	}
+}
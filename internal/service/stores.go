@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/repository"
+	"time"
+)
+
+// Storage interfaces used by the services, implemented by the PostgreSQL
+// repositories. Services depend on these so they can be tested with fakes.
+
+type PdfStore interface {
+	CreateWithFile(ctx context.Context, pdf *model.PdfFile, publish func() error) error
+	CreateFromUpload(ctx context.Context, pdf *model.PdfFile, sessionID string, publish func() error) error
+	FindByID(ctx context.Context, id int64) (*model.PdfFile, error)
+	FindByFilename(ctx context.Context, filename string) (*model.PdfFile, error)
+	FindAll(ctx context.Context, status string, page, limit int) ([]model.PdfFile, int64, error)
+	SoftDelete(ctx context.Context, id int64) error
+	UpdateScanResult(ctx context.Context, id int64, status model.PdfStatus, result string) error
+	FindPendingScan(ctx context.Context, afterID int64, limit int) ([]model.PdfFile, error)
+	Each(ctx context.Context, fn func(*model.PdfFile) error) error
+	MarkMissing(ctx context.Context, id int64) error
+}
+
+type UserStore interface {
+	CreateUser(ctx context.Context, user *model.User) error
+	FindUserByEmail(ctx context.Context, email string) (*model.User, error)
+	FindUserByID(ctx context.Context, id int64) (*model.User, error)
+	FindUserByOIDC(ctx context.Context, issuer, subject string) (*model.User, error)
+	LinkOIDC(ctx context.Context, id int64, issuer, subject string) error
+	FindAllUsers(ctx context.Context, active *bool, page, limit int) ([]model.User, int64, error)
+	UpdateUser(ctx context.Context, user *model.User, modifiedBy int64) error
+	MarkEmailVerified(ctx context.Context, id int64) error
+	UpdatePassword(ctx context.Context, id int64, hashedPassword string, modifiedBy int64) error
+	RoleExists(ctx context.Context, id int64) (bool, error)
+	FindRoleByName(ctx context.Context, name string) (*model.Role, error)
+	FindAllRoles(ctx context.Context) ([]model.Role, error)
+	CountActiveUsersWithRole(ctx context.Context, roleID int64) (int64, error)
+}
+
+type TokenStore interface {
+	CreateRefreshToken(ctx context.Context, t *model.RefreshToken) error
+	FindRefreshTokenByHash(ctx context.Context, hash string) (*model.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldID int64, next *model.RefreshToken) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID int64) error
+	RevokeJTI(ctx context.Context, jti string, userID int64, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string, userID int64, issuedAt time.Time) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+	CreatePasswordReset(ctx context.Context, userID int64, hash string, expiresAt time.Time, ip string) error
+	FindPasswordReset(ctx context.Context, hash string) (int64, error)
+	ConsumePasswordReset(ctx context.Context, hash string) (int64, error)
+}
+
+type UploadSessionStore interface {
+	Create(ctx context.Context, s *model.UploadSession) error
+	FindByID(ctx context.Context, id string) (*model.UploadSession, error)
+	AdvanceOffset(ctx context.Context, id string, from, to int64, expiresAt time.Time) (bool, error)
+	UpdateStatus(ctx context.Context, id string, status model.UploadSessionStatus) error
+	FindExpired(ctx context.Context, now time.Time) ([]model.UploadSession, error)
+}
+
+type APIKeyStore interface {
+	Create(ctx context.Context, k *model.APIKey) error
+	FindByHash(ctx context.Context, hash string) (*model.APIKey, error)
+	FindByUser(ctx context.Context, userID int64) ([]model.APIKey, error)
+	Revoke(ctx context.Context, id, userID int64) (*model.APIKey, error)
+	TouchLastUsed(ctx context.Context, id int64, at time.Time) error
+}
+
+type AuditStore interface {
+	Create(ctx context.Context, e *model.AuditEvent) error
+	FindAll(ctx context.Context, f model.AuditFilter) ([]model.AuditEvent, int64, error)
+	Each(ctx context.Context, f model.AuditFilter, fn func(*model.AuditEvent) error) error
+}
+
+type ShareLinkStore interface {
+	Create(ctx context.Context, l *model.ShareLink) error
+	FindByHash(ctx context.Context, hash string) (*model.ShareLink, error)
+	FindActiveByPdf(ctx context.Context, pdfID int64, now time.Time) ([]model.ShareLink, error)
+	Revoke(ctx context.Context, id, pdfID int64) (*model.ShareLink, error)
+	ClaimDownload(ctx context.Context, id int64, now time.Time) (bool, error)
+	RecordFailedPassword(ctx context.Context, id int64, maxFailures int, now time.Time) (bool, error)
+	RecordAccess(ctx context.Context, a *model.ShareLinkAccess) error
+	FindAccesses(ctx context.Context, id, pdfID int64, limit int) ([]model.ShareLinkAccess, error)
+}
+
+var (
+	_ PdfStore           = (*repository.PdfRepository)(nil)
+	_ UserStore          = (*repository.UserRepository)(nil)
+	_ TokenStore         = (*repository.TokenRepository)(nil)
+	_ UploadSessionStore = (*repository.UploadSessionRepository)(nil)
+	_ APIKeyStore        = (*repository.APIKeyRepository)(nil)
+	_ AuditStore         = (*repository.AuditRepository)(nil)
+	_ ShareLinkStore     = (*repository.ShareLinkRepository)(nil)
+	_ LoginAttemptStore  = (*repository.LoginAttemptRepository)(nil)
+	_ LoginAuditor       = (*repository.LoginAttemptRepository)(nil)
+)
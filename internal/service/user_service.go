@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"pdf-management-system/internal/config"
+	"pdf-management-system/internal/model"
+	"pdf-management-system/internal/validation"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Nama role yang dibuat oleh migrasi 0007
+const (
+	AdminRoleName   = "Admin"
+	DefaultRoleName = "Staff"
+)
+
+var (
+	ErrUserNotFound        = errors.New("user not found")
+	ErrCannotModifySelf    = errors.New("admins cannot deactivate themselves or remove their own admin role")
+	ErrLastAdmin           = errors.New("at least one active admin must remain")
+	ErrAccountDisabled     = errors.New("account has been deactivated")
+	ErrDefaultRoleNotFound = errors.New("default role for registration does not exist")
+)
+
+// UserService backs the admin user management API.
+type UserService struct {
+	Repo   UserStore
+	Tokens TokenStore
+	Guard  *LoginGuard
+}
+
+func NewUserService(repo UserStore, tokens TokenStore, guard *LoginGuard) *UserService {
+	return &UserService{Repo: repo, Tokens: tokens, Guard: guard}
+}
+
+func (s *UserService) ListUsers(ctx context.Context, active *bool, page, limit int) ([]model.User, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	return s.Repo.FindAllUsers(ctx, active, page, limit)
+}
+
+func (s *UserService) GetUser(ctx context.Context, id int64) (*model.User, error) {
+	user, err := s.Repo.FindUserByID(ctx, id)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+func (s *UserService) ListRoles(ctx context.Context) ([]model.Role, error) {
+	return s.Repo.FindAllRoles(ctx)
+}
+
+// CreateUser creates an active account with the requested role. The email is
+// trusted because an admin entered it, so the account starts out verified.
+func (s *UserService) CreateUser(ctx context.Context, req model.CreateUserRequest, actorID int64) (*model.User, error) {
+	req.Email = strings.TrimSpace(req.Email)
+	errs := validation.ValidateCreateUser(req)
+	if err := s.checkRole(ctx, &errs, req.RoleID); err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if existing, _ := s.Repo.FindUserByEmail(ctx, req.Email); existing != nil {
+		errs.Add("email", validation.CodeAlreadyExists, "email already registered")
+		return nil, errs
+	}
+
+	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		Name:            req.Name,
+		Email:           req.Email,
+		Password:        string(hashedPwd),
+		Address:         req.Address,
+		PhoneNumber:     req.PhoneNumber,
+		PostCode:        req.PostCode,
+		RoleID:          req.RoleID,
+		IsEmailVerified: true,
+		IsActive:        true,
+		CreatedBy:       &actorID,
+		CreatedDate:     time.Now(),
+	}
+	if err := s.Repo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, user.ID)
+}
+
+// UpdateUser applies a partial update. Deactivating a user or changing their
+// role revokes all of their sessions so the change takes effect immediately.
+func (s *UserService) UpdateUser(ctx context.Context, id int64, req model.UpdateUserRequest, actorID int64) (*model.User, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := validation.ValidateUpdateUser(req)
+	if req.RoleID != nil && *req.RoleID > 0 {
+		if err := s.checkRole(ctx, &errs, *req.RoleID); err != nil {
+			return nil, err
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	oldRole, wasActive := user.RoleID, user.IsActive
+	if req.Name != nil {
+		user.Name = *req.Name
+	}
+	if req.Address != nil {
+		user.Address = *req.Address
+	}
+	if req.PhoneNumber != nil {
+		user.PhoneNumber = *req.PhoneNumber
+	}
+	if req.PostCode != nil {
+		user.PostCode = *req.PostCode
+	}
+	if req.RoleID != nil {
+		user.RoleID = *req.RoleID
+	}
+	if req.IsActive != nil {
+		user.IsActive = *req.IsActive
+	}
+
+	if err := s.checkAdminRemains(ctx, user, oldRole, wasActive, actorID); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repo.UpdateUser(ctx, user, actorID); err != nil {
+		return nil, err
+	}
+
+	// Role ada di dalam access token, jadi sesi lama harus dicabut
+	if (wasActive && !user.IsActive) || oldRole != user.RoleID {
+		if err := s.Tokens.RevokeAllForUser(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	// Dibaca ulang supaya role_name ikut terbaru
+	return s.GetUser(ctx, user.ID)
+}
+
+func (s *UserService) DeactivateUser(ctx context.Context, id int64, actorID int64) (*model.User, error) {
+	inactive := false
+	return s.UpdateUser(ctx, id, model.UpdateUserRequest{IsActive: &inactive}, actorID)
+}
+
+// UpdateProfile lets a user edit their own contact details.
+func (s *UserService) UpdateProfile(ctx context.Context, userID int64, req model.UpdateProfileRequest) (*model.User, error) {
+	return s.UpdateUser(ctx, userID, model.UpdateUserRequest{
+		Name:        req.Name,
+		Address:     req.Address,
+		PhoneNumber: req.PhoneNumber,
+		PostCode:    req.PostCode,
+	}, userID)
+}
+
+// UnlockLogin clears the failed-login lockout of a user and optionally of an IP address.
+func (s *UserService) UnlockLogin(ctx context.Context, id int64, ip string, admin model.ClientInfo) (*model.User, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.Guard == nil {
+		return user, nil
+	}
+	if err := s.Guard.Unlock(ctx, user.Email, ip, &user.ID, admin.IP); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// EnsureBootstrapAdmin creates the cfg.Email / cfg.Password account when
+// no active admin exists yet, so a fresh install can log in to the admin API.
+func (s *UserService) EnsureBootstrapAdmin(ctx context.Context, cfg config.AdminConfig) error {
+	email := strings.TrimSpace(cfg.Email)
+	password := cfg.Password
+	if email == "" || password == "" {
+		return nil
+	}
+
+	adminRole, err := s.Repo.FindRoleByName(ctx, AdminRoleName)
+	if err != nil {
+		return err
+	}
+	n, err := s.Repo.CountActiveUsersWithRole(ctx, adminRole.ID)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	if existing, _ := s.Repo.FindUserByEmail(ctx, email); existing != nil {
+		slog.Warn("bootstrap admin skipped, email exists but is not an active admin", "email", email)
+		return nil
+	}
+
+	name := cfg.Name
+	if name == "" {
+		name = "Administrator"
+	}
+
+	var errs validation.Errors
+	validation.Password(&errs, "ADMIN_PASSWORD", password, email)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	hashedPwd, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user := &model.User{
+		Name:            name,
+		Email:           email,
+		Password:        string(hashedPwd),
+		RoleID:          adminRole.ID,
+		IsEmailVerified: true,
+		IsActive:        true,
+		CreatedDate:     time.Now(),
+	}
+	if err := s.Repo.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	slog.Info("bootstrap admin created", "email", email, "target_user_id", user.ID)
+	return nil
+}
+
+func (s *UserService) checkRole(ctx context.Context, errs *validation.Errors, roleID int64) error {
+	if roleID <= 0 {
+		return nil
+	}
+	exists, err := s.Repo.RoleExists(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		errs.Add("role_id", validation.CodeNotFound, "role does not exist")
+	}
+	return nil
+}
+
+// checkAdminRemains stops an admin from locking themselves out and keeps at
+// least one active admin in the system.
+func (s *UserService) checkAdminRemains(ctx context.Context, user *model.User, oldRole int64, wasActive bool, actorID int64) error {
+	adminRole, err := s.Repo.FindRoleByName(ctx, AdminRoleName)
+	if err != nil {
+		return err
+	}
+	wasAdmin := wasActive && oldRole == adminRole.ID
+	isAdmin := user.IsActive && user.RoleID == adminRole.ID
+	if !wasAdmin || isAdmin {
+		return nil
+	}
+
+	if user.ID == actorID {
+		return ErrCannotModifySelf
+	}
+	n, err := s.Repo.CountActiveUsersWithRole(ctx, adminRole.ID)
+	if err != nil {
+		return err
+	}
+	if n <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
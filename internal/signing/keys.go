@@ -0,0 +1,228 @@
+// Package signing holds the keys used to sign and verify our JWTs.
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is one signing or verification key, identified by its kid.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	// Sign holds the private key ([]byte for HMAC); nil for verify-only keys.
+	Sign interface{}
+	// Verify holds the public key ([]byte for HMAC).
+	Verify interface{}
+}
+
+// KeySet signs with one key and verifies with every configured key, so old
+// keys can stay valid for verification while tokens are rotated to a new one.
+type KeySet struct {
+	signing *Key
+	verify  map[string]*Key
+}
+
+// NewKeySet builds a set that signs with signingKey and also accepts the extra verify keys.
+func NewKeySet(signingKey *Key, verify ...*Key) (*KeySet, error) {
+	if signingKey == nil || signingKey.Sign == nil {
+		return nil, errors.New("signing key is required")
+	}
+	ks := &KeySet{signing: signingKey, verify: map[string]*Key{signingKey.ID: signingKey}}
+	for _, k := range verify {
+		if _, dup := ks.verify[k.ID]; dup {
+			return nil, fmt.Errorf("duplicate key id %q", k.ID)
+		}
+		ks.verify[k.ID] = k
+	}
+	return ks, nil
+}
+
+// Load builds the key set from the JWT config:
+//
+//	privateKeyFile  PEM RSA (RS256) or Ed25519 (EdDSA) private key used for signing (JWT_PRIVATE_KEY_FILE)
+//	secret          HS256 secret; signs when no private key is set, otherwise verify-only (JWT_SECRET)
+//	verifyKeyFiles  PEM keys still accepted for verification, rotated out (JWT_VERIFY_KEY_FILES)
+//
+// File entries may be written as kid=path, otherwise the kid is derived from the public key.
+// There is no default: starting without any key is an error.
+func Load(privateKeyFile, secret string, verifyKeyFiles []string) (*KeySet, error) {
+	var signingKey *Key
+	var verify []*Key
+
+	if spec := strings.TrimSpace(privateKeyFile); spec != "" {
+		k, err := LoadKeyFile(spec)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign == nil {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE %s does not contain a private key", spec)
+		}
+		signingKey = k
+	}
+
+	if secret != "" {
+		k := HMACKey(secret)
+		if signingKey == nil {
+			signingKey = k
+		} else {
+			// Tetap diterima supaya token HS256 lama valid sampai kedaluwarsa
+			k.Sign = nil
+			verify = append(verify, k)
+		}
+	}
+
+	if signingKey == nil {
+		return nil, errors.New("no JWT signing key configured: set JWT_PRIVATE_KEY_FILE or JWT_SECRET")
+	}
+
+	for _, spec := range verifyKeyFiles {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		k, err := LoadKeyFile(spec)
+		if err != nil {
+			return nil, err
+		}
+		k.Sign = nil
+		verify = append(verify, k)
+	}
+
+	return NewKeySet(signingKey, verify...)
+}
+
+// HMACKey returns an HS256 key; its kid is derived from the secret hash.
+func HMACKey(secret string) *Key {
+	sum := sha256.Sum256([]byte("kid:" + secret))
+	return &Key{ID: "hs-" + hex.EncodeToString(sum[:6]), Method: jwt.SigningMethodHS256, Sign: []byte(secret), Verify: []byte(secret)}
+}
+
+// LoadKeyFile reads a PEM key from "path" or "kid=path".
+func LoadKeyFile(spec string) (*Key, error) {
+	kid, path := "", spec
+	if id, p, ok := strings.Cut(spec, "="); ok {
+		kid, path = strings.TrimSpace(id), strings.TrimSpace(p)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key %s: %v", path, err)
+	}
+	k, err := ParsePEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("JWT key %s: %v", path, err)
+	}
+	if kid != "" {
+		k.ID = kid
+	}
+	return k, nil
+}
+
+// ParsePEM parses an RSA or Ed25519 private key (PKCS#1/PKCS#8) or public key (PKIX/PKCS#1).
+func ParsePEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var priv, pub interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if priv != nil {
+		signer, ok := priv.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key type")
+		}
+		pub = signer.Public()
+	}
+
+	k := &Key{Sign: priv, Verify: pub}
+	switch p := pub.(type) {
+	case *rsa.PublicKey:
+		if p.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		k.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		k.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", pub)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	k.ID = hex.EncodeToString(sum[:8])
+	return k, nil
+}
+
+// Sign signs claims with the signing key and sets the kid header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.Sign)
+}
+
+// Parse verifies a token against the key named by its kid header. Tokens
+// without a kid (issued before kids were added) are checked with the only key
+// of their algorithm.
+func (ks *KeySet) Parse(tokenString string, opts ...jwt.ParserOption) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		var key *Key
+		if kid, ok := token.Header["kid"].(string); ok {
+			if key, ok = ks.verify[kid]; !ok {
+				return nil, fmt.Errorf("unknown key id %q", kid)
+			}
+		} else {
+			for _, k := range ks.verify {
+				if k.Method.Alg() == token.Method.Alg() {
+					if key != nil {
+						return nil, errors.New("token has no key id and several keys match")
+					}
+					key = k
+				}
+			}
+			if key == nil {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+		}
+		// Algoritma harus sesuai kunci, cegah alg confusion (misal RS256 -> HS256)
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.Verify, nil
+	}, opts...)
+}
+
+// SigningKeyID returns the kid of the current signing key.
+func (ks *KeySet) SigningKeyID() string {
+	return ks.signing.ID
+}
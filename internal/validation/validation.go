@@ -0,0 +1,252 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"pdf-management-system/internal/model"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Error codes returned in model.FieldError.Code
+const (
+	CodeRequired      = "REQUIRED"
+	CodeInvalidFormat = "INVALID_FORMAT"
+	CodeTooLong       = "TOO_LONG"
+	CodeTooShort      = "TOO_SHORT"
+	CodeWeakPassword  = "WEAK_PASSWORD"
+	CodeNotFound      = "NOT_FOUND"
+	CodeAlreadyExists = "ALREADY_EXISTS"
+)
+
+// Batas mengikuti ukuran kolom di tabel users
+const (
+	MaxEmailLength    = 255
+	MaxNameLength     = 50
+	MaxAddressLength  = 255
+	MaxPhoneLength    = 13
+	PostCodeLength    = 5
+	MinPasswordLength = 8
+	MaxPasswordLength = 72 // bcrypt ignores bytes after 72
+)
+
+var (
+	phonePattern    = regexp.MustCompile(`^\+?[0-9]{8,12}$`)
+	postCodePattern = regexp.MustCompile(`^[0-9]{5}$`)
+)
+
+// Errors is a list of field errors; it implements error so services can return it.
+type Errors []model.FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+func (e *Errors) Add(field, code, message string) {
+	*e = append(*e, model.FieldError{Field: field, Code: code, Message: message})
+}
+
+// Err returns nil when there are no errors, so callers can `return errs.Err()`.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func ValidateRegister(req model.RegisterRequest) Errors {
+	var errs Errors
+	required(&errs, "name", req.Name)
+	maxLen(&errs, "name", req.Name, MaxNameLength)
+	email(&errs, "email", req.Email)
+	Password(&errs, "password", req.Password, req.Email)
+	profile(&errs, req.Address, req.PhoneNumber, req.PostCode)
+	return errs
+}
+
+func ValidateCreateUser(req model.CreateUserRequest) Errors {
+	errs := ValidateRegister(model.RegisterRequest{
+		Name:        req.Name,
+		Email:       req.Email,
+		Password:    req.Password,
+		Address:     req.Address,
+		PhoneNumber: req.PhoneNumber,
+		PostCode:    req.PostCode,
+	})
+	if req.RoleID <= 0 {
+		errs.Add("role_id", CodeRequired, "is required")
+	}
+	return errs
+}
+
+func ValidateUpdateUser(req model.UpdateUserRequest) Errors {
+	var errs Errors
+	if req.Name != nil {
+		required(&errs, "name", *req.Name)
+		maxLen(&errs, "name", *req.Name, MaxNameLength)
+	}
+	var address, phone, postCode string
+	if req.Address != nil {
+		address = *req.Address
+	}
+	if req.PhoneNumber != nil {
+		phone = *req.PhoneNumber
+	}
+	if req.PostCode != nil {
+		postCode = *req.PostCode
+	}
+	profile(&errs, address, phone, postCode)
+	if req.RoleID != nil && *req.RoleID <= 0 {
+		errs.Add("role_id", CodeRequired, "is required")
+	}
+	return errs
+}
+
+func ValidateLogin(req model.LoginRequest) Errors {
+	var errs Errors
+	required(&errs, "email", req.Email)
+	maxLen(&errs, "email", req.Email, MaxEmailLength)
+	required(&errs, "password", req.Password)
+	return errs
+}
+
+func ValidateGeneratePdf(req model.GeneratePdfRequest) Errors {
+	var errs Errors
+	required(&errs, "title", req.Title)
+	maxLen(&errs, "title", req.Title, 200)
+	required(&errs, "institution_name", req.InstitutionName)
+	maxLen(&errs, "institution_name", req.InstitutionName, 200)
+	maxLen(&errs, "address", req.Address, 255)
+	maxLen(&errs, "phone", req.Phone, 50)
+	required(&errs, "content", req.Content)
+	maxLen(&errs, "content", req.Content, 100000)
+	if req.LogoURL != "" {
+		u, err := url.Parse(req.LogoURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.Add("logo_url", CodeInvalidFormat, "must be an http or https URL")
+		}
+		maxLen(&errs, "logo_url", req.LogoURL, 2048)
+	}
+	return errs
+}
+
+func ValidateCreateAPIKey(req model.CreateAPIKeyRequest, now time.Time) Errors {
+	var errs Errors
+	required(&errs, "name", req.Name)
+	maxLen(&errs, "name", req.Name, 100)
+	for _, scope := range req.Scopes {
+		if !slices.Contains(model.APIKeyScopes, scope) {
+			errs.Add("scopes", CodeInvalidFormat, fmt.Sprintf("unknown scope %q, allowed: %s", scope, strings.Join(model.APIKeyScopes, ", ")))
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		errs.Add("expires_at", CodeInvalidFormat, "must be in the future")
+	}
+	return errs
+}
+
+// ValidateCreateShareLink checks expires_at against maxTTL and the optional
+// download limit. An empty password means the link is not protected.
+func ValidateCreateShareLink(req model.CreateShareLinkRequest, now time.Time, maxTTL time.Duration) Errors {
+	var errs Errors
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			errs.Add("expires_at", CodeInvalidFormat, "must be in the future")
+		} else if req.ExpiresAt.After(now.Add(maxTTL)) {
+			errs.Add("expires_at", CodeInvalidFormat, fmt.Sprintf("must be at most %s from now", maxTTL))
+		}
+	}
+	if req.Password != "" {
+		if utf8.RuneCountInString(req.Password) < MinPasswordLength {
+			errs.Add("password", CodeTooShort, fmt.Sprintf("must be at least %d characters", MinPasswordLength))
+		} else if len(req.Password) > MaxPasswordLength {
+			errs.Add("password", CodeTooLong, fmt.Sprintf("must be at most %d bytes", MaxPasswordLength))
+		}
+	}
+	if req.MaxDownloads != nil && *req.MaxDownloads < 1 {
+		errs.Add("max_downloads", CodeInvalidFormat, "must be at least 1")
+	}
+	return errs
+}
+
+// Password applies the password strength policy: 8-72 bytes, at least one
+// letter and one digit, and not the same as the account email.
+func Password(errs *Errors, field, password, accountEmail string) {
+	if password == "" {
+		errs.Add(field, CodeRequired, "is required")
+		return
+	}
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		errs.Add(field, CodeTooShort, fmt.Sprintf("must be at least %d characters", MinPasswordLength))
+		return
+	}
+	if len(password) > MaxPasswordLength {
+		errs.Add(field, CodeTooLong, fmt.Sprintf("must be at most %d bytes", MaxPasswordLength))
+		return
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		errs.Add(field, CodeWeakPassword, "must contain at least one letter and one digit")
+		return
+	}
+	if accountEmail != "" && strings.EqualFold(password, accountEmail) {
+		errs.Add(field, CodeWeakPassword, "must not be the same as the email")
+	}
+}
+
+// profile checks the optional contact fields against the users column sizes.
+func profile(errs *Errors, address, phone, postCode string) {
+	maxLen(errs, "address", address, MaxAddressLength)
+	if phone != "" && !phonePattern.MatchString(phone) {
+		errs.Add("phone_number", CodeInvalidFormat, fmt.Sprintf("must be 8-12 digits, optionally prefixed with +, max %d characters", MaxPhoneLength))
+	}
+	if postCode != "" && !postCodePattern.MatchString(postCode) {
+		errs.Add("post_code", CodeInvalidFormat, fmt.Sprintf("must be exactly %d digits", PostCodeLength))
+	}
+}
+
+func email(errs *Errors, field, value string) {
+	if strings.TrimSpace(value) == "" {
+		errs.Add(field, CodeRequired, "is required")
+		return
+	}
+	if len(value) > MaxEmailLength {
+		errs.Add(field, CodeTooLong, fmt.Sprintf("must be at most %d characters", MaxEmailLength))
+		return
+	}
+	// Tolak bentuk "Nama <a@b.c>" yang juga diterima mail.ParseAddress
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value || !strings.Contains(value[strings.LastIndex(value, "@"):], ".") {
+		errs.Add(field, CodeInvalidFormat, "must be a valid email address")
+	}
+}
+
+func required(errs *Errors, field, value string) {
+	if strings.TrimSpace(value) == "" {
+		errs.Add(field, CodeRequired, "is required")
+	}
+}
+
+func maxLen(errs *Errors, field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		errs.Add(field, CodeTooLong, fmt.Sprintf("must be at most %d characters", max))
+	}
+}